@@ -189,8 +189,10 @@ resource "cdnvideo_http" "edu" {
       ]
     }
   }
-  locations = {
-    "path_to_content" = {
+  location = [
+    {
+      path  = "/path_to_content"
+      match = "prefix"
       cache = {
         disable       = false
         consider_args = true
@@ -358,8 +360,13 @@ resource "cdnvideo_http" "edu" {
       #   }
       # ]
       return_http_status_code = 403
+    },
+    {
+      path                    = "\\.(exe|msi)$"
+      match                   = "regex_case_insensitive"
+      return_http_status_code = 403
     }
-  }
+  ]
 }
 
 output "edu_resource" {
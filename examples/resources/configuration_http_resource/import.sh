@@ -0,0 +1,6 @@
+# CDN http resources can be imported by their id
+terraform import cdnvideo_http.example 12345
+
+# or by their name or cdn domain, which must match exactly one resource
+terraform import cdnvideo_http.example name:example
+terraform import cdnvideo_http.example domain:example.a.trbcdn.net
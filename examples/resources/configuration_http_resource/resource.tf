@@ -17,8 +17,9 @@ resource "cdnvideo_http" "edu" {
     connect_timeout = "10s"
     aws = {
       auth = {
-        access_key = "string"
-        secret_key = "string"
+        access_key            = "string"
+        secret_key_wo         = "string"
+        secret_key_wo_version = 1
       }
     }
     s3_bucket  = "string"
@@ -175,8 +176,10 @@ resource "cdnvideo_http" "edu" {
       ]
     }
   }
-  locations = {
-    "path_to_content" = {
+  location = [
+    {
+      path  = "/path_to_content"
+      match = "prefix"
       cache = {
         disable       = false
         consider_args = true
@@ -344,6 +347,11 @@ resource "cdnvideo_http" "edu" {
         }
       ]
       return_http_status_code = 403
+    },
+    {
+      path                    = "\\.(exe|msi)$"
+      match                   = "regex_case_insensitive"
+      return_http_status_code = 403
     }
-  }
+  ]
 }
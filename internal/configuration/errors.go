@@ -0,0 +1,101 @@
+package configuration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError describes a request rejected by the CDNVideo api, either with a
+// non-200 HTTP status or with a response status other than "accept".
+type APIError struct {
+	HTTPStatus  int
+	Status      string
+	Message     string
+	Description string
+	TaskId      string
+	// Field is the dotted path of the resource attribute the error refers to, if any.
+	Field string
+	Body  []byte
+}
+
+// apiErrorBody is the error payload returned by the api.
+type apiErrorBody struct {
+	Status      json.RawMessage `json:"status"`
+	Message     string          `json:"message"`
+	Description string          `json:"description"`
+	TaskId      string          `json:"task_id"`
+	Field       string          `json:"field"`
+}
+
+// newAPIError builds an APIError from a response, filling in whatever
+// details the body provides.
+func newAPIError(http_status int, body []byte) *APIError {
+	api_error := &APIError{HTTPStatus: http_status, Body: body}
+
+	payload := apiErrorBody{}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		// Status is a string for configuration api and a number for oauth
+		var status string
+		if json.Unmarshal(payload.Status, &status) == nil {
+			api_error.Status = status
+		}
+		api_error.Message = payload.Message
+		api_error.Description = payload.Description
+		api_error.TaskId = payload.TaskId
+		api_error.Field = payload.Field
+	}
+
+	return api_error
+}
+
+func (e *APIError) Error() string {
+	parts := []string{}
+	if e.HTTPStatus != http.StatusOK {
+		parts = append(parts, fmt.Sprintf("status: %d", e.HTTPStatus))
+	}
+	if e.Message != "" {
+		parts = append(parts, "message: "+e.Message)
+	}
+	if e.Description != "" {
+		parts = append(parts, "description: "+e.Description)
+	}
+	if e.Field != "" {
+		parts = append(parts, "field: "+e.Field)
+	}
+	if e.Message == "" && e.Description == "" && len(e.Body) > 0 {
+		parts = append(parts, "body: "+string(e.Body))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "status: "+e.Status)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// IsNotFound reports whether err is an api error for a missing resource.
+func IsNotFound(err error) bool {
+	return hasHTTPStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is an api error caused by a conflicting resource state.
+func IsConflict(err error) bool {
+	return hasHTTPStatus(err, http.StatusConflict)
+}
+
+// IsRateLimited reports whether err is an api error caused by rate limiting.
+func IsRateLimited(err error) bool {
+	return hasHTTPStatus(err, http.StatusTooManyRequests)
+}
+
+// IsUnauthorized reports whether err is an api error caused by invalid credentials.
+func IsUnauthorized(err error) bool {
+	return hasHTTPStatus(err, http.StatusUnauthorized) || hasHTTPStatus(err, http.StatusForbidden)
+}
+
+func hasHTTPStatus(err error, status int) bool {
+	var api_error *APIError
+	return errors.As(err, &api_error) && api_error.HTTPStatus == status
+}
@@ -0,0 +1,82 @@
+package configuration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMakeRequestReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status": "error", "message": "not found", "description": "resource 42 does not exist"}`))
+	}))
+	defer server.Close()
+
+	proxy := &ConfigurationApiProxy{HTTPClient: server.Client(), ApiURL: server.URL, AccountName: "account", Auth: AuthStruct{Token: "token"}}
+
+	_, err := proxy.GetHttpResource(context.Background(), "42")
+
+	var api_error *APIError
+	if !errors.As(err, &api_error) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if api_error.HTTPStatus != http.StatusNotFound || api_error.Status != "error" ||
+		api_error.Message != "not found" || api_error.Description != "resource 42 does not exist" {
+		t.Errorf("unexpected api error: %+v", api_error)
+	}
+	if !IsNotFound(err) || IsConflict(err) || IsRateLimited(err) {
+		t.Errorf("unexpected error kind for %v", err)
+	}
+	if !IsNotFound(fmt.Errorf("wrapped: %w", err)) {
+		t.Error("expected IsNotFound to unwrap errors")
+	}
+}
+
+func TestParseTaskResponseRejected(t *testing.T) {
+	body := []byte(`{"status": "reject", "task_id": "t-1", "message": "validation failed", "description": "bad port", "field": "origin.servers.example.com.port"}`)
+
+	_, err := parseTaskResponse(body)
+
+	var api_error *APIError
+	if !errors.As(err, &api_error) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if api_error.HTTPStatus != http.StatusOK || api_error.Status != "reject" || api_error.TaskId != "t-1" ||
+		api_error.Field != "origin.servers.example.com.port" || string(api_error.Body) != string(body) {
+		t.Errorf("unexpected api error: %+v", api_error)
+	}
+
+	expected := "message: validation failed, description: bad port, field: origin.servers.example.com.port"
+	if api_error.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, api_error.Error())
+	}
+}
+
+func TestParseTaskResponseAccepted(t *testing.T) {
+	response, err := parseTaskResponse([]byte(`{"status": "accept", "task_id": "t-1", "resource_id": "42"}`))
+	if err != nil {
+		t.Fatalf("parseTaskResponse: %s", err)
+	}
+	if response.TaskId != "t-1" || response.ResourceId != "42" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+}
+
+func TestAPIErrorWithoutDetails(t *testing.T) {
+	err := newAPIError(http.StatusBadGateway, []byte("<html>bad gateway</html>"))
+
+	expected := "status: 502, body: <html>bad gateway</html>"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+
+	// Oauth responds with a numeric status
+	err = newAPIError(http.StatusUnauthorized, []byte(`{"status": 401, "message": "bad credentials"}`))
+	if err.Status != "" || err.Message != "bad credentials" || !IsUnauthorized(err) {
+		t.Errorf("unexpected api error: %+v", err)
+	}
+}
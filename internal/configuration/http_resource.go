@@ -1,42 +1,44 @@
 package configuration
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
-)
 
-const ConfigurationApiURL string = "https://api.cdnvideo.ru/cdn/api/v1/%s/resource/http/%s"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
 
 type CdnHttpResource struct {
-	ID                 string               `json:"id,omitempty"`
-	Name               string               `json:"name,omitempty"`
-	CreationTs         int64                `json:"creation_ts,omitempty"`
-	CdnDomain          string               `json:"cdn_domain,omitempty"`
-	Active             *bool                `json:"active,omitempty"`
-	Origin             *Origin              `json:"origin,omitempty"`
-	Cache              *Cache               `json:"cache,omitempty"`
-	Certificate        *int64               `json:"certificate,omitempty"`
-	Tuning             *string              `json:"tuning,omitempty"`
-	SliceSizeMegabytes *int64               `json:"slice_size_megabytes,omitempty"`
-	ModernTlsOnly      *bool                `json:"modern_tls_only,omitempty"`
-	StrongSslCiphers   *bool                `json:"strong_ssl_ciphers,omitempty"`
-	FollowRedirects    *bool                `json:"follow_redirects,omitempty"`
-	NoHttp2            *bool                `json:"no_http2,omitempty"`
-	Http2Https         *bool                `json:"http2https,omitempty"`
-	HttpsOnly          *bool                `json:"https_only,omitempty"`
-	UseHttp3           *bool                `json:"use_http3,omitempty"`
-	Compress           *Compress            `json:"compress,omitempty"`
-	Robots             *Robots              `json:"robots,omitempty"`
-	Auth               *Auth                `json:"auth,omitempty"`
-	Headers            *Headers             `json:"headers,omitempty"`
-	Cors               *Cors                `json:"cors,omitempty"`
-	Names              []string             `json:"names,omitempty"`
-	Limitations        *Limitations         `json:"limitations,omitempty"`
-	IOSS               *bool                `json:"ioss,omitempty"`
-	Packaging          *Packaging           `json:"packaging,omitempty"`
-	Locations          map[string]Locations `json:"locations,omitempty"`
+	ID                 string       `json:"id,omitempty"`
+	Name               string       `json:"name,omitempty"`
+	CreationTs         int64        `json:"creation_ts,omitempty"`
+	CdnDomain          string       `json:"cdn_domain,omitempty"`
+	Active             *bool        `json:"active,omitempty"`
+	Origin             *Origin      `json:"origin,omitempty"`
+	Cache              *Cache       `json:"cache,omitempty"`
+	Certificate        *int64       `json:"certificate,omitempty"`
+	Tuning             *string      `json:"tuning,omitempty"`
+	SliceSizeMegabytes *int64       `json:"slice_size_megabytes,omitempty"`
+	ModernTlsOnly      *bool        `json:"modern_tls_only,omitempty"`
+	StrongSslCiphers   *bool        `json:"strong_ssl_ciphers,omitempty"`
+	FollowRedirects    *bool        `json:"follow_redirects,omitempty"`
+	NoHttp2            *bool        `json:"no_http2,omitempty"`
+	Http2Https         *bool        `json:"http2https,omitempty"`
+	HttpsOnly          *bool        `json:"https_only,omitempty"`
+	UseHttp3           *bool        `json:"use_http3,omitempty"`
+	Compress           *Compress    `json:"compress,omitempty"`
+	Robots             *Robots      `json:"robots,omitempty"`
+	Auth               *Auth        `json:"auth,omitempty"`
+	Headers            *Headers     `json:"headers,omitempty"`
+	Cors               *Cors        `json:"cors,omitempty"`
+	Names              []string     `json:"names,omitempty"`
+	Limitations        *Limitations `json:"limitations,omitempty"`
+	IOSS               *bool        `json:"ioss,omitempty"`
+	Packaging          *Packaging   `json:"packaging,omitempty"`
+	Locations          LocationList `json:"locations,omitempty"`
 }
 
 type CdnHttpResourceCreated struct {
@@ -61,10 +63,19 @@ type Origin struct {
 }
 
 type AWS struct {
-	Auth *struct {
-		AccessKey *string `json:"access_key,omitempty" tfsdk:"access_key"`
-		SecretKey *string `json:"secret_key,omitempty" tfsdk:"secret_key"`
-	} `json:"auth,omitempty" tfsdk:"auth"`
+	Auth *AWSAuth `json:"auth,omitempty" tfsdk:"auth"`
+}
+
+// AWSAuth holds the origin aws keys. The write-only variants and their
+// versions only exist in the provider schema and are never sent as such,
+// their values are sent as access_key and secret_key.
+type AWSAuth struct {
+	AccessKey          *string `json:"access_key,omitempty" tfsdk:"access_key"`
+	AccessKeyWO        *string `json:"-" tfsdk:"access_key_wo"`
+	AccessKeyWOVersion *int64  `json:"-" tfsdk:"access_key_wo_version"`
+	SecretKey          *string `json:"secret_key,omitempty" tfsdk:"secret_key"`
+	SecretKeyWO        *string `json:"-" tfsdk:"secret_key_wo"`
+	SecretKeyWOVersion *int64  `json:"-" tfsdk:"secret_key_wo_version"`
 }
 
 type Servers struct {
@@ -101,13 +112,19 @@ type Robots struct {
 }
 
 type Auth struct {
-	URL       *string `json:"url,omitempty" tfsdk:"url"`
-	Forbidden *bool   `json:"forbidden,omitempty" tfsdk:"forbidden"`
-	Md5       *struct {
-		Secret   *string `json:"secret,omitempty" tfsdk:"secret"`
-		Forever  *bool   `json:"forever,omitempty" tfsdk:"forever"`
-		Anywhere *bool   `json:"anywhere,omitempty" tfsdk:"anywhere"`
-	} `json:"md5,omitempty" tfsdk:"md5"`
+	URL       *string  `json:"url,omitempty" tfsdk:"url"`
+	Forbidden *bool    `json:"forbidden,omitempty" tfsdk:"forbidden"`
+	Md5       *Md5Auth `json:"md5,omitempty" tfsdk:"md5"`
+}
+
+// Md5Auth holds the signed url settings. Like AWSAuth, the write-only
+// secret is sent as secret.
+type Md5Auth struct {
+	Secret          *string `json:"secret,omitempty" tfsdk:"secret"`
+	SecretWO        *string `json:"-" tfsdk:"secret_wo"`
+	SecretWOVersion *int64  `json:"-" tfsdk:"secret_wo_version"`
+	Forever         *bool   `json:"forever,omitempty" tfsdk:"forever"`
+	Anywhere        *bool   `json:"anywhere,omitempty" tfsdk:"anywhere"`
 }
 
 type Headers struct {
@@ -186,6 +203,132 @@ type Locations struct {
 	ReturnHTTPStatusCode *int         `json:"return_http_status_code,omitempty" tfsdk:"return_http_status_code"`
 }
 
+// Location match types, in the api they are written as nginx location
+// modifiers in front of the path.
+const (
+	LocationMatchPrefix               = "prefix"
+	LocationMatchPriorityPrefix       = "priority_prefix"
+	LocationMatchExact                = "exact"
+	LocationMatchRegex                = "regex"
+	LocationMatchRegexCaseInsensitive = "regex_case_insensitive"
+)
+
+var locationMatchModifiers = map[string]string{
+	LocationMatchPriorityPrefix:       "^~ ",
+	LocationMatchExact:                "= ",
+	LocationMatchRegex:                "~ ",
+	LocationMatchRegexCaseInsensitive: "~* ",
+}
+
+// Location is a rule for requests matching Path. A nil Match means
+// LocationMatchPrefix.
+type Location struct {
+	Path  string  `json:"-" tfsdk:"path"`
+	Match *string `json:"-" tfsdk:"match"`
+	Locations
+}
+
+// Key returns the api key of the location, the path prefixed with the
+// modifier of its match type.
+func (l Location) Key() string {
+	if l.Match == nil {
+		return l.Path
+	}
+	return locationMatchModifiers[*l.Match] + l.Path
+}
+
+// ParseLocationKey splits an api location key into its match type and path.
+func ParseLocationKey(key string) (match, path string) {
+	// "~* " is checked before "~ " as they share the tilde
+	for _, match := range []string{LocationMatchPriorityPrefix, LocationMatchExact, LocationMatchRegexCaseInsensitive, LocationMatchRegex} {
+		if path, ok := strings.CutPrefix(key, locationMatchModifiers[match]); ok {
+			return match, path
+		}
+	}
+	return LocationMatchPrefix, key
+}
+
+// LocationList is the ordered list of location rules. The api represents it
+// as an object keyed by Location.Key, in which the order of keys matters.
+type LocationList []Location
+
+// Keys returns the api keys of the locations in order.
+func (l LocationList) Keys() []string {
+	keys := make([]string, 0, len(l))
+	for _, location := range l {
+		keys = append(keys, location.Key())
+	}
+	return keys
+}
+
+func (l LocationList) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("null"), nil
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, location := range l {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		key, err := json.Marshal(location.Key())
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(location.Locations)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+func (l *LocationList) UnmarshalJSON(body []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		*l = nil
+		return nil
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("locations must be an object, got %v", token)
+	}
+
+	locations := LocationList{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("unexpected location key %v", token)
+		}
+
+		match, path := ParseLocationKey(key)
+		location := Location{Path: path, Match: &match}
+		if err := decoder.Decode(&location.Locations); err != nil {
+			return fmt.Errorf("location %q: %w", key, err)
+		}
+		locations = append(locations, location)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	*l = locations
+	return nil
+}
+
 type Packaging struct {
 	Mp4 *struct {
 		OutputProtocols *[]string `json:"output_protocols,omitempty" tfsdk:"output_protocols"`
@@ -197,8 +340,12 @@ type Rewrite struct {
 	Flag *string `json:"flag,omitempty" tfsdk:"flag"`
 }
 
-func (proxy *ConfigurationApiProxy) GetHttpResources() ([]CdnHttpResource, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf(ConfigurationApiURL, proxy.AccountName, ""), nil)
+func (proxy *ConfigurationApiProxy) httpResourceURL(resource_id string) string {
+	return fmt.Sprintf("%s/%s/resource/http/%s", strings.TrimRight(proxy.ApiURL, "/"), proxy.AccountName, resource_id)
+}
+
+func (proxy *ConfigurationApiProxy) GetHttpResources(ctx context.Context) ([]CdnHttpResource, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", proxy.httpResourceURL(""), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -216,13 +363,13 @@ func (proxy *ConfigurationApiProxy) GetHttpResources() ([]CdnHttpResource, error
 	return resources, nil
 }
 
-func (proxy *ConfigurationApiProxy) CreateHttpResource(httpResource CdnHttpResource) (*CdnHttpResourceCreated, error) {
+func (proxy *ConfigurationApiProxy) CreateHttpResource(ctx context.Context, httpResource CdnHttpResource) (*CdnHttpResourceCreated, error) {
 	rb, err := json.Marshal(httpResource)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf(ConfigurationApiURL, proxy.AccountName, ""), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "POST", proxy.httpResourceURL(""), strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
@@ -231,21 +378,14 @@ func (proxy *ConfigurationApiProxy) CreateHttpResource(httpResource CdnHttpResou
 		return nil, err
 	}
 
-	response := CdnHttpResourceCreated{}
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return nil, err
-	}
-	// TODO: make custom error
-	if response.Status != "accept" {
-		return nil, fmt.Errorf("message: %s, description: %s", response.Message, response.Description)
-	}
-	return &response, nil
+	return parseTaskResponse(body)
 }
 
-func (proxy *ConfigurationApiProxy) GetHttpResource(resource_id string) (CdnHttpResource, error) {
+func (proxy *ConfigurationApiProxy) GetHttpResource(ctx context.Context, resource_id string) (CdnHttpResource, error) {
+	ctx = tflog.SetField(ctx, "cdn_resource_id", resource_id)
+
 	resource := CdnHttpResource{}
-	req, err := http.NewRequest("GET", fmt.Sprintf(ConfigurationApiURL, proxy.AccountName, resource_id), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", proxy.httpResourceURL(resource_id), nil)
 	if err != nil {
 		return resource, err
 	}
@@ -262,13 +402,15 @@ func (proxy *ConfigurationApiProxy) GetHttpResource(resource_id string) (CdnHttp
 }
 
 // TODO: change response struct (without resource id)
-func (proxy *ConfigurationApiProxy) UpdateHttpResource(httpResource CdnHttpResource, resource_id string) (*CdnHttpResourceCreated, error) {
+func (proxy *ConfigurationApiProxy) UpdateHttpResource(ctx context.Context, httpResource CdnHttpResource, resource_id string) (*CdnHttpResourceCreated, error) {
+	ctx = tflog.SetField(ctx, "cdn_resource_id", resource_id)
+
 	rb, err := json.Marshal(httpResource)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("PUT", fmt.Sprintf(ConfigurationApiURL, proxy.AccountName, resource_id), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "PUT", proxy.httpResourceURL(resource_id), strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
@@ -277,43 +419,82 @@ func (proxy *ConfigurationApiProxy) UpdateHttpResource(httpResource CdnHttpResou
 		return nil, err
 	}
 
-	response := CdnHttpResourceCreated{}
-	err = json.Unmarshal(body, &response)
+	return parseTaskResponse(body)
+}
+
+// PatchHttpResource applies a merge patch to the resource, leaving every
+// field not mentioned in it untouched.
+func (proxy *ConfigurationApiProxy) PatchHttpResource(ctx context.Context, patch MergePatch, resource_id string) (*CdnHttpResourceCreated, error) {
+	ctx = tflog.SetField(ctx, "cdn_resource_id", resource_id)
+
+	rb, err := json.Marshal(patch)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.Status != "accept" {
-		return nil, fmt.Errorf("message: %s, description: %s", response.Message, response.Description)
+	req, err := http.NewRequestWithContext(ctx, "PATCH", proxy.httpResourceURL(resource_id), strings.NewReader(string(rb)))
+	if err != nil {
+		return nil, err
+	}
+	body, err := proxy.MakeRequest(req)
+	if err != nil {
+		return nil, err
 	}
-	return &response, nil
 
+	return parseTaskResponse(body)
 }
-func (proxy *ConfigurationApiProxy) DeactivateHttpResource(resource_id string) error {
+
+func (proxy *ConfigurationApiProxy) DeactivateHttpResource(ctx context.Context, resource_id string) (*CdnHttpResourceCreated, error) {
+	ctx = tflog.SetField(ctx, "cdn_resource_id", resource_id)
+
 	active := false
 	httpResource := CdnHttpResource{Active: &active}
 
 	rb, err := json.Marshal(httpResource)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req, err := http.NewRequest("PATCH", fmt.Sprintf(ConfigurationApiURL, proxy.AccountName, resource_id), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", proxy.httpResourceURL(resource_id), strings.NewReader(string(rb)))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	body, err := proxy.MakeRequest(req)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return parseTaskResponse(body)
+}
+
+// DeleteHttpResource permanently removes the resource, freeing its name.
+func (proxy *ConfigurationApiProxy) DeleteHttpResource(ctx context.Context, resource_id string) (*CdnHttpResourceCreated, error) {
+	ctx = tflog.SetField(ctx, "cdn_resource_id", resource_id)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", proxy.httpResourceURL(resource_id), nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := proxy.MakeRequest(req)
+	if err != nil {
+		return nil, err
 	}
 
+	return parseTaskResponse(body)
+}
+
+// parseTaskResponse decodes the api answer to a modifying request and turns
+// any status other than "accept" into an APIError.
+func parseTaskResponse(body []byte) (*CdnHttpResourceCreated, error) {
 	response := CdnHttpResourceCreated{}
-	err = json.Unmarshal(body, &response)
+	err := json.Unmarshal(body, &response)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if response.Status != "accept" {
-		return fmt.Errorf("message: %s, description: %s", response.Message, response.Description)
+		api_error := newAPIError(http.StatusOK, body)
+		api_error.Status = response.Status
+		return nil, api_error
 	}
-	return nil
+	return &response, nil
 }
@@ -0,0 +1,56 @@
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeleteHttpResource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/account/resource/http/42" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"status": "accept", "task_id": "task-1"}`))
+	}))
+	defer server.Close()
+
+	proxy := &ConfigurationApiProxy{HTTPClient: server.Client(), ApiURL: server.URL, AccountName: "account", Auth: AuthStruct{Token: "token"}}
+
+	response, err := proxy.DeleteHttpResource(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("DeleteHttpResource: %s", err)
+	}
+	if response.TaskId != "task-1" {
+		t.Errorf("unexpected task id: %s", response.TaskId)
+	}
+}
+
+func TestLocationListJSON(t *testing.T) {
+	body := `{"locations":{"/video":{"ioss":true},"~* \\.mp4$":{},"= /":{"return_http_status_code":204},"~ ^/a":{},"^~ /img":{}}}`
+
+	var http_resource CdnHttpResource
+	if err := json.Unmarshal([]byte(body), &http_resource); err != nil {
+		t.Fatal(err)
+	}
+
+	matches := []string{}
+	for _, location := range http_resource.Locations {
+		matches = append(matches, *location.Match+" "+location.Path)
+	}
+	expected := `[prefix /video regex_case_insensitive \.mp4$ exact / regex ^/a priority_prefix /img]`
+	if actual := fmt.Sprint(matches); actual != expected {
+		t.Errorf("expected locations %s, got %s", expected, actual)
+	}
+
+	encoded, err := json.Marshal(http_resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != body {
+		t.Errorf("expected %s, got %s", body, encoded)
+	}
+}
@@ -0,0 +1,134 @@
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// logSubsystem is the tflog subsystem used for api traffic. Its level can be
+	// set separately with TF_LOG_PROVIDER_CDNVIDEO_API.
+	logSubsystem = "cdnvideo_api"
+
+	redactedValue = "***"
+)
+
+// sensitiveKeys are json fields, form values and headers whose values are
+// never written to the log: the oauth password and issued token, the auth
+// token header, aws.auth.secret_key and auth.md5.secret.
+var sensitiveKeys = map[string]bool{
+	"password":       true,
+	"token":          true,
+	"cdn-auth-token": true,
+	"secret_key":     true,
+	"secret":         true,
+}
+
+// apiLogContext returns ctx with the api traffic logging subsystem.
+func apiLogContext(ctx context.Context) context.Context {
+	return tflog.NewSubsystem(ctx, logSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_CDNVIDEO_API"))
+}
+
+// logRequest writes the outgoing request with secrets redacted.
+func logRequest(ctx context.Context, req *http.Request) {
+	fields := map[string]any{
+		"method":  req.Method,
+		"url":     req.URL.String(),
+		"headers": redactHeaders(req.Header),
+	}
+
+	if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+		if reader, err := req.GetBody(); err == nil {
+			body, _ := io.ReadAll(reader)
+			reader.Close()
+			fields["body"] = redactBody(body, req.Header.Get("Content-Type"))
+		}
+	}
+
+	tflog.SubsystemTrace(ctx, logSubsystem, "Sending CDNVideo API request", fields)
+}
+
+// logResponse writes the received response with secrets redacted.
+func logResponse(ctx context.Context, req *http.Request, res *http.Response, body []byte, latency time.Duration) {
+	tflog.SubsystemTrace(ctx, logSubsystem, "Received CDNVideo API response", map[string]any{
+		"method":     req.Method,
+		"url":        req.URL.String(),
+		"status":     res.StatusCode,
+		"latency_ms": latency.Milliseconds(),
+		"headers":    redactHeaders(res.Header),
+		"body":       redactBody(body, res.Header.Get("Content-Type")),
+	})
+}
+
+// redactHeaders flattens headers for logging, hiding sensitive values.
+func redactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for key, values := range header {
+		if sensitiveKeys[strings.ToLower(key)] || strings.EqualFold(key, "Authorization") {
+			redacted[key] = redactedValue
+			continue
+		}
+		redacted[key] = strings.Join(values, ", ")
+	}
+	return redacted
+}
+
+// redactBody returns body as a string with sensitive form values or json
+// fields replaced. Bodies that cannot be parsed are returned unchanged.
+func redactBody(body []byte, content_type string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if media_type, _, _ := mime.ParseMediaType(content_type); media_type == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			// Form bodies carry the oauth credentials, do not risk logging them
+			return redactedValue
+		}
+		for key := range values {
+			if sensitiveKeys[key] {
+				values.Set(key, redactedValue)
+			}
+		}
+		return values.Encode()
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return string(body)
+	}
+
+	redacted, err := json.Marshal(redactJSON(decoded))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+// redactJSON replaces the values of sensitive keys at any depth.
+func redactJSON(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			if sensitiveKeys[key] && nested != nil {
+				value[key] = redactedValue
+				continue
+			}
+			value[key] = redactJSON(nested)
+		}
+	case []any:
+		for i, nested := range value {
+			value[i] = redactJSON(nested)
+		}
+	}
+	return value
+}
@@ -0,0 +1,121 @@
+package configuration
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestMakeRequestLogsRedactedTraffic(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_CDNVIDEO_API", "TRACE")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": 200, "lifetime": 3600, "token": "issued-token"}`))
+	})
+	mux.HandleFunc("/api/account/resource/http/42", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "accept", "task_id": "1"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	proxy, err := NewProxy(context.Background(), ProxyConfig{
+		Username:    "user",
+		Password:    "very-secret-password",
+		AccountName: "account",
+		ApiURL:      server.URL + "/api",
+		OauthURL:    server.URL + "/oauth/token/",
+	})
+	if err != nil {
+		t.Fatalf("NewProxy: %s", err)
+	}
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	secret_key := "aws-secret-key"
+	md5_secret := "md5-secret"
+	http_resource := CdnHttpResource{Name: "test", Origin: &Origin{AWS: &AWS{}}, Auth: &Auth{}}
+	http_resource.Origin.AWS.Auth = &AWSAuth{SecretKey: &secret_key}
+	http_resource.Auth.Md5 = &Md5Auth{Secret: &md5_secret}
+
+	if _, err := proxy.UpdateHttpResource(ctx, http_resource, "42"); err != nil {
+		t.Fatalf("UpdateHttpResource: %s", err)
+	}
+
+	logged := output.String()
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("decoding log: %s", err)
+	}
+
+	var requests, responses int
+	for _, entry := range entries {
+		switch entry["@message"] {
+		case "Sending CDNVideo API request":
+			requests++
+			if entry["method"] == nil || entry["url"] == nil {
+				t.Errorf("request entry without method or url: %v", entry)
+			}
+		case "Received CDNVideo API response":
+			responses++
+			if entry["status"] == nil || entry["latency_ms"] == nil || entry["body"] == nil {
+				t.Errorf("response entry without status, latency or body: %v", entry)
+			}
+		}
+	}
+	if requests != 2 || responses != 2 {
+		t.Errorf("expected oauth and update to be logged, got %d requests and %d responses", requests, responses)
+	}
+
+	for _, secret := range []string{"very-secret-password", "issued-token", secret_key, md5_secret} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("log contains secret %q", secret)
+		}
+	}
+	if !strings.Contains(logged, `\"name\":\"test\"`) {
+		t.Errorf("log does not contain the request body: %s", logged)
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	cases := map[string]struct {
+		body         string
+		content_type string
+		expected     string
+	}{
+		"form": {
+			body:         "password=pass&username=user",
+			content_type: "application/x-www-form-urlencoded",
+			expected:     "password=%2A%2A%2A&username=user",
+		},
+		"invalid form": {
+			body:         "password=pa%ss&username=user",
+			content_type: "application/x-www-form-urlencoded",
+			expected:     "***",
+		},
+		"nested json": {
+			body:     `{"locations":{"/":{"origin":{"aws":{"auth":{"access_key":"a","secret_key":"s"}}}}}}`,
+			expected: `{"locations":{"/":{"origin":{"aws":{"auth":{"access_key":"a","secret_key":"***"}}}}}}`,
+		},
+		"json list": {
+			body:     `[{"auth":{"md5":{"secret":"s","forever":true}}}]`,
+			expected: `[{"auth":{"md5":{"forever":true,"secret":"***"}}}]`,
+		},
+		"not json": {
+			body:     "Bad Gateway",
+			expected: "Bad Gateway",
+		},
+	}
+
+	for name, c := range cases {
+		if redacted := redactBody([]byte(c.body), c.content_type); redacted != c.expected {
+			t.Errorf("%s: expected %s, got %s", name, c.expected, redacted)
+		}
+	}
+}
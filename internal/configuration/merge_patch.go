@@ -0,0 +1,249 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// MergePatch is a JSON merge patch (RFC 7396): only the listed fields are
+// changed, nested objects are merged and null removes a field.
+type MergePatch map[string]any
+
+// NewMergePatch returns the merge patch turning the prior resource into the
+// planned one. Fields that are equal in both are left out, so fields the
+// provider does not model and unrelated changes made elsewhere are kept.
+func NewMergePatch(prior, planned CdnHttpResource) (MergePatch, error) {
+	prior_fields, err := jsonFields(prior)
+	if err != nil {
+		return nil, err
+	}
+	planned_fields, err := jsonFields(planned)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := diffFields(prior_fields, planned_fields)
+
+	// A merge patch can not move keys, so reordered locations are sent in
+	// full, in their planned order
+	if !keepsLocationOrder(prior.Locations, planned.Locations) {
+		prior_locations, _ := prior_fields["locations"].(map[string]any)
+		planned_locations, _ := planned_fields["locations"].(map[string]any)
+		locations, err := orderedReplacement(planned.Locations.Keys(), prior_locations, planned_locations)
+		if err != nil {
+			return nil, err
+		}
+		patch["locations"] = locations
+	}
+
+	return patch, nil
+}
+
+// keepsLocationOrder reports whether merging the planned locations into the
+// prior ones results in the planned order: locations kept from prior are in
+// the same order and new ones are added at the end.
+func keepsLocationOrder(prior, planned LocationList) bool {
+	merged := commonKeys(prior, planned)
+	prior_keys := map[string]bool{}
+	for _, key := range prior.Keys() {
+		prior_keys[key] = true
+	}
+	for _, key := range planned.Keys() {
+		if !prior_keys[key] {
+			merged = append(merged, key)
+		}
+	}
+
+	return reflect.DeepEqual(merged, planned.Keys())
+}
+
+// commonKeys returns the keys of locations that other has too, in the order
+// of locations.
+func commonKeys(locations, other LocationList) []string {
+	other_keys := map[string]bool{}
+	for _, key := range other.Keys() {
+		other_keys[key] = true
+	}
+	keys := []string{}
+	for _, key := range locations.Keys() {
+		if other_keys[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// orderedReplacement returns an object with the planned values under keys, in
+// that order, followed by nulls for prior keys that were removed. Planned
+// objects are replacements too, fields missing from them are set to null.
+func orderedReplacement(keys []string, prior, planned map[string]any) (json.RawMessage, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	write := func(key string, value any) error {
+		if buffer.Len() > 1 {
+			buffer.WriteByte(',')
+		}
+		encoded_key, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		encoded_value, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buffer.Write(encoded_key)
+		buffer.WriteByte(':')
+		buffer.Write(encoded_value)
+		return nil
+	}
+
+	for _, key := range keys {
+		prior_object, _ := prior[key].(map[string]any)
+		if err := write(key, replacementFields(prior_object, planned[key])); err != nil {
+			return nil, err
+		}
+	}
+	for key := range prior {
+		if _, ok := planned[key]; !ok {
+			if err := write(key, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// replacementFields returns planned with null for every field of prior it
+// does not have, so that merging it replaces prior.
+func replacementFields(prior map[string]any, planned any) any {
+	planned_object, ok := planned.(map[string]any)
+	if !ok {
+		return planned
+	}
+
+	replacement := map[string]any{}
+	for key, value := range planned_object {
+		prior_object, _ := prior[key].(map[string]any)
+		replacement[key] = replacementFields(prior_object, value)
+	}
+	for key := range prior {
+		if _, ok := planned_object[key]; !ok {
+			replacement[key] = nil
+		}
+	}
+	return replacement
+}
+
+// jsonFields returns the json representation of value as generic fields.
+func jsonFields(value any) (map[string]any, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func diffFields(prior, planned map[string]any) MergePatch {
+	patch := MergePatch{}
+
+	for key, planned_value := range planned {
+		prior_value, ok := prior[key]
+		if ok && reflect.DeepEqual(prior_value, planned_value) {
+			continue
+		}
+
+		prior_object, prior_is_object := prior_value.(map[string]any)
+		planned_object, planned_is_object := planned_value.(map[string]any)
+		if ok && prior_is_object && planned_is_object {
+			patch[key] = diffFields(prior_object, planned_object)
+			continue
+		}
+
+		patch[key] = planned_value
+	}
+
+	for key := range prior {
+		if _, ok := planned[key]; !ok {
+			patch[key] = nil
+		}
+	}
+
+	return patch
+}
+
+// FieldChange is a difference between two versions of a resource. Path is
+// the dotted path of the json field, missing values are nil.
+type FieldChange struct {
+	Path    string
+	Prior   any
+	Current any
+}
+
+// DiffFields lists the fields that differ between two versions of a
+// resource, sorted by path. Values of secret fields are redacted. Locations
+// kept in a different order are reported as a change of "locations" listing
+// their keys.
+func DiffFields(prior, current CdnHttpResource) ([]FieldChange, error) {
+	prior_fields, err := jsonFields(prior)
+	if err != nil {
+		return nil, err
+	}
+	current_fields, err := jsonFields(current)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	collectChanges("", prior_fields, current_fields, &changes)
+	if !reflect.DeepEqual(commonKeys(prior.Locations, current.Locations), commonKeys(current.Locations, prior.Locations)) {
+		changes = append(changes, FieldChange{Path: "locations", Prior: prior.Locations.Keys(), Current: current.Locations.Keys()})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	return changes, nil
+}
+
+func collectChanges(prefix string, prior, current map[string]any, changes *[]FieldChange) {
+	keys := map[string]bool{}
+	for key := range prior {
+		keys[key] = true
+	}
+	for key := range current {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		prior_value, current_value := prior[key], current[key]
+		if reflect.DeepEqual(prior_value, current_value) {
+			continue
+		}
+
+		prior_object, prior_is_object := prior_value.(map[string]any)
+		current_object, current_is_object := current_value.(map[string]any)
+		if prior_is_object && current_is_object {
+			collectChanges(prefix+key+".", prior_object, current_object, changes)
+			continue
+		}
+
+		if sensitiveKeys[key] {
+			prior_value, current_value = redactField(prior_value), redactField(current_value)
+		}
+		*changes = append(*changes, FieldChange{Path: prefix + key, Prior: prior_value, Current: current_value})
+	}
+}
+
+func redactField(value any) any {
+	if value == nil {
+		return nil
+	}
+	return redactedValue
+}
@@ -0,0 +1,102 @@
+package configuration
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewMergePatch(t *testing.T) {
+	tuning := "large"
+	prior := CdnHttpResource{Name: "test", Names: []string{"a.example.com", "b.example.com"}, Tuning: &tuning}
+	planned := CdnHttpResource{Name: "test", Names: []string{"a.example.com"}}
+
+	patch, err := NewMergePatch(prior, planned)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(patch)
+	if expected := `{"names":["a.example.com"],"tuning":null}`; string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	if patch, _ := NewMergePatch(prior, prior); len(patch) != 0 {
+		t.Errorf("expected empty patch, got %v", patch)
+	}
+}
+
+func TestNewMergePatchReorderedLocations(t *testing.T) {
+	exact := LocationMatchExact
+	disabled, enabled := false, true
+	prior := CdnHttpResource{Name: "test", Locations: LocationList{
+		{Path: "/video", Locations: Locations{IOSS: &enabled}},
+		{Path: "/images", Locations: Locations{IOSS: &enabled}},
+		{Path: "/", Match: &exact},
+	}}
+
+	// Added locations are merged at the end
+	planned := CdnHttpResource{Name: "test", Locations: append(LocationList{}, prior.Locations...)}
+	planned.Locations[1] = Location{Path: "/images", Locations: Locations{IOSS: &disabled}}
+	planned.Locations = append(planned.Locations, Location{Path: "/audio"})
+	patch, err := NewMergePatch(prior, planned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(patch)
+	if expected := `{"locations":{"/audio":{},"/images":{"ioss":false}}}`; string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	// Moved locations are sent in full, in order
+	planned = CdnHttpResource{Name: "test", Locations: LocationList{
+		{Path: "/", Match: &exact},
+		{Path: "/video"},
+	}}
+	patch, err = NewMergePatch(prior, planned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = json.Marshal(patch)
+	if expected := `{"locations":{"= /":{},"/video":{"ioss":null},"/images":null}}`; string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	changes, err := DiffFields(prior, planned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = json.Marshal(changes)
+	expected := `[{"Path":"locations","Prior":["/video","/images","= /"],"Current":["= /","/video"]},` +
+		`{"Path":"locations./images","Prior":{"ioss":true},"Current":null},` +
+		`{"Path":"locations./video.ioss","Prior":true,"Current":null}]`
+	if string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	tuning := "large"
+	prior_secret, current_secret := "old", "new"
+	prior := CdnHttpResource{Name: "test", Tuning: &tuning, Auth: &Auth{}}
+	prior.Auth.Md5 = &Md5Auth{Secret: &prior_secret}
+	current := CdnHttpResource{Name: "renamed", Names: []string{"a.example.com"}, Auth: &Auth{}}
+	current.Auth.Md5 = &Md5Auth{Secret: &current_secret}
+
+	changes, err := DiffFields(prior, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(changes)
+	expected := `[{"Path":"auth.md5.secret","Prior":"***","Current":"***"},` +
+		`{"Path":"name","Prior":"test","Current":"renamed"},` +
+		`{"Path":"names","Prior":null,"Current":["a.example.com"]},` +
+		`{"Path":"tuning","Prior":"large","Current":null}]`
+	if string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	if changes, _ := DiffFields(prior, prior); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
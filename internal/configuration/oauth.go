@@ -1,34 +1,39 @@
 package configuration
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
 	"strings"
 )
 
-const OauthURL string = "https://api.cdnvideo.ru/app/oauth/v1/token/"
-
 type AuthResponse struct {
-	Status   int    `json:"status"`
+	Status int `json:"status"`
+	// Lifetime of the token in seconds
 	Lifetime int    `json:"lifetime"`
 	Token    string `json:"token"`
 }
 
-func (proxy *ConfigurationApiProxy) GetToken(username, password *string) (*AuthResponse, error) {
+func (proxy *ConfigurationApiProxy) GetToken(ctx context.Context, username, password *string) (*AuthResponse, error) {
 	data := url.Values{}
 	data.Set("username", *username)
 	data.Set("password", *password)
-	req, err := http.NewRequest("POST", OauthURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", proxy.OauthURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	body, err := proxy.MakeRequest(req)
+	body, status, err := proxy.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
 
+	if status != http.StatusOK {
+		return nil, newAPIError(status, body)
+	}
+
 	ar := AuthResponse{}
 	err = json.Unmarshal(body, &ar)
 	if err != nil {
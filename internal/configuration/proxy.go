@@ -1,61 +1,296 @@
 package configuration
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	DefaultApiURL   string = "https://api.cdnvideo.ru/cdn/api/v1"
+	DefaultOauthURL string = "https://api.cdnvideo.ru/app/oauth/v1/token/"
+
+	// tokenRefreshMargin is how long before expiry the token is proactively refreshed.
+	tokenRefreshMargin = 60 * time.Second
 )
 
 type ConfigurationApiProxy struct {
 	HTTPClient  *http.Client
 	Auth        AuthStruct
 	AccountName string
+	ApiURL      string
+	OauthURL    string
+	Retry       RetryConfig
+
+	// authMutex guards Auth so concurrent resource operations share one refresh.
+	authMutex sync.Mutex
 }
 
 type AuthStruct struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Token    string `json:"token"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"-"`
 }
 
-func NewProxy(username, password, account_name *string) (*ConfigurationApiProxy, error) {
+// ProxyConfig holds the settings used to create a ConfigurationApiProxy.
+// Empty ApiURL or OauthURL fall back to the public CDNVideo endpoints.
+// A static Token is used as is instead of exchanging Username and Password.
+type ProxyConfig struct {
+	Username    string
+	Password    string
+	Token       string
+	AccountName string
+	ApiURL      string
+	OauthURL    string
+	Retry       RetryConfig
+}
+
+// ErrNoCredentials is returned by requests made without a static token or
+// username and password to obtain one.
+var ErrNoCredentials = errors.New("no CDNVideo API credentials configured: set api_token, or username and password")
+
+// ErrNoAccountName is returned by requests made without an account name,
+// which every api url contains.
+var ErrNoAccountName = errors.New("no CDNVideo account name configured: set account_name")
+
+// NewProxy creates a configuration api client. No request is sent until the
+// client is used, the auth token is obtained on the first api call.
+func NewProxy(ctx context.Context, config ProxyConfig) (*ConfigurationApiProxy, error) {
 	proxy := ConfigurationApiProxy{
 		HTTPClient:  &http.Client{Timeout: 10 * time.Second},
-		AccountName: *account_name,
+		AccountName: config.AccountName,
+		ApiURL:      DefaultApiURL,
+		OauthURL:    DefaultOauthURL,
+		Retry:       config.Retry,
 		Auth: AuthStruct{
-			Username: *username,
-			Password: *password,
+			Username: config.Username,
+			Password: config.Password,
+			Token:    config.Token,
 		},
 	}
 
-	response, err := proxy.GetToken(username, password)
-	if err != nil {
-		return nil, err
+	if config.ApiURL != "" {
+		proxy.ApiURL = config.ApiURL
 	}
 
-	proxy.Auth.Token = response.Token
+	if config.OauthURL != "" {
+		proxy.OauthURL = config.OauthURL
+	}
+
+	if err := validateURL(proxy.ApiURL); err != nil {
+		return nil, fmt.Errorf("invalid api url: %w", err)
+	}
+
+	if err := validateURL(proxy.OauthURL); err != nil {
+		return nil, fmt.Errorf("invalid oauth url: %w", err)
+	}
+
+	tflog.Debug(ctx, "Created CDNVideo API client, authentication is deferred until the first request")
 
 	return &proxy, nil
 }
 
+// MakeRequest sends an authorized request to the configuration api.
+// An expiring token is refreshed beforehand, and a request rejected with
+// 401 is replayed once with a freshly obtained token.
 func (proxy *ConfigurationApiProxy) MakeRequest(req *http.Request) ([]byte, error) {
-	req.Header.Set("cdn-auth-token", proxy.Auth.Token)
+	ctx := req.Context()
 
-	res, err := proxy.HTTPClient.Do(req)
+	if proxy.AccountName == "" {
+		return nil, ErrNoAccountName
+	}
+
+	token, err := proxy.validToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("cdn-auth-token", token)
+
+	body, status, err := proxy.doRequest(req)
 	if err != nil {
 		return nil, err
 	}
+
+	if status == http.StatusUnauthorized && proxy.canRefreshToken() {
+		tflog.Debug(ctx, "CDNVideo API token rejected, re-authenticating")
+		token, err = proxy.refreshToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err = rewindRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("cdn-auth-token", token)
+
+		body, status, err = proxy.doRequest(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if status != http.StatusOK {
+		return nil, newAPIError(status, body)
+	}
+
+	return body, nil
+}
+
+// doRequest sends the request, retrying transient failures according to
+// proxy.Retry, and returns the last response body and status code.
+func (proxy *ConfigurationApiProxy) doRequest(req *http.Request) ([]byte, int, error) {
+	for attempt := 1; ; attempt++ {
+		body, status, header, err := proxy.send(req)
+		if attempt >= proxy.Retry.MaxAttempts || !shouldRetry(req.Method, status, err) {
+			return body, status, err
+		}
+
+		delay := proxy.Retry.backoff(attempt, header)
+		tflog.Debug(req.Context(), "Retrying CDNVideo API request", map[string]any{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"status":  status,
+			"attempt": attempt,
+			"delay":   delay.String(),
+		})
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, 0, err
+		}
+
+		req, err = rewindRequest(req)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// send performs a single round trip and reads the whole response body.
+// Both the request and the response are logged at trace level.
+func (proxy *ConfigurationApiProxy) send(req *http.Request) ([]byte, int, http.Header, error) {
+	ctx := apiLogContext(req.Context())
+	logRequest(ctx, req)
+
+	start := time.Now()
+	res, err := proxy.HTTPClient.Do(req)
+	if err != nil {
+		tflog.SubsystemTrace(ctx, logSubsystem, "CDNVideo API request failed", map[string]any{
+			"method":     req.Method,
+			"url":        req.URL.String(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"error":      err.Error(),
+		})
+		return nil, 0, nil, err
+	}
 	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return nil, 0, nil, err
+	}
+	logResponse(ctx, req, res, body, time.Since(start))
+
+	return body, res.StatusCode, res.Header, nil
+}
+
+// validToken returns the current token, refreshing it first if it is missing
+// or about to expire.
+func (proxy *ConfigurationApiProxy) validToken(ctx context.Context) (string, error) {
+	proxy.authMutex.Lock()
+	token, expires_at := proxy.Auth.Token, proxy.Auth.ExpiresAt
+	proxy.authMutex.Unlock()
+
+	if token != "" && (expires_at.IsZero() || time.Now().Before(expires_at)) {
+		return token, nil
+	}
+
+	return proxy.refreshToken(ctx, token)
+}
+
+// canRefreshToken reports whether a new token can be obtained, which is not
+// the case for a static api token.
+func (proxy *ConfigurationApiProxy) canRefreshToken() bool {
+	return proxy.Auth.Username != "" || proxy.Auth.Password != ""
+}
+
+// refreshToken obtains a new token unless another caller has already
+// replaced stale_token with a valid one while we were waiting for the lock.
+func (proxy *ConfigurationApiProxy) refreshToken(ctx context.Context, stale_token string) (string, error) {
+	proxy.authMutex.Lock()
+	defer proxy.authMutex.Unlock()
+
+	if proxy.Auth.Token != "" && proxy.Auth.Token != stale_token &&
+		(proxy.Auth.ExpiresAt.IsZero() || time.Now().Before(proxy.Auth.ExpiresAt)) {
+		return proxy.Auth.Token, nil
+	}
+
+	if !proxy.canRefreshToken() {
+		return "", ErrNoCredentials
+	}
+
+	tflog.Debug(ctx, "Obtaining CDNVideo API token")
+	response, err := proxy.GetToken(ctx, &proxy.Auth.Username, &proxy.Auth.Password)
+	if err != nil {
+		return "", err
 	}
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status: %d, body: %s", res.StatusCode, body)
+	proxy.Auth.Token = response.Token
+	proxy.Auth.ExpiresAt = tokenExpiry(time.Now(), response.Lifetime)
+
+	return proxy.Auth.Token, nil
+}
+
+// tokenExpiry returns the moment after which a token with the given lifetime
+// in seconds should no longer be used. Zero lifetime means unknown expiry.
+func tokenExpiry(issued_at time.Time, lifetime int) time.Time {
+	if lifetime <= 0 {
+		return time.Time{}
+	}
+
+	ttl := time.Duration(lifetime) * time.Second
+	margin := tokenRefreshMargin
+	if margin > ttl/2 {
+		margin = ttl / 2
+	}
+
+	return issued_at.Add(ttl - margin)
+}
+
+// validateURL checks that raw_url is an absolute http or https url.
+func validateURL(raw_url string) error {
+	parsed, err := url.Parse(raw_url)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%q is not an absolute http or https url", raw_url)
+	}
+	return nil
+}
+
+// rewindRequest returns a copy of req with a fresh body so it can be sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	replay := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return replay, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request body of %s %s cannot be replayed", req.Method, req.URL)
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
 	}
+	replay.Body = body
 
-	return body, err
+	return replay, nil
 }
@@ -0,0 +1,319 @@
+package configuration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewProxyCustomURLs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected oauth method: %s", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.PostForm.Get("username") != "user" || r.PostForm.Get("password") != "pass" {
+			t.Errorf("unexpected credentials: %v", r.PostForm)
+		}
+		w.Write([]byte(`{"status": 200, "lifetime": 3600, "token": "test-token"}`))
+	})
+	mux.HandleFunc("/api/account/resource/http/42", func(w http.ResponseWriter, r *http.Request) {
+		if token := r.Header.Get("cdn-auth-token"); token != "test-token" {
+			t.Errorf("unexpected token: %q", token)
+		}
+		w.Write([]byte(`{"id": "42", "name": "test"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	proxy, err := NewProxy(context.Background(), ProxyConfig{
+		Username:    "user",
+		Password:    "pass",
+		AccountName: "account",
+		ApiURL:      server.URL + "/api/",
+		OauthURL:    server.URL + "/oauth/token/",
+	})
+	if err != nil {
+		t.Fatalf("NewProxy: %s", err)
+	}
+
+	resource, err := proxy.GetHttpResource(context.Background(), "42")
+	if err != nil {
+		t.Fatalf("GetHttpResource: %s", err)
+	}
+	if resource.ID != "42" || resource.Name != "test" {
+		t.Errorf("unexpected resource: %+v", resource)
+	}
+}
+
+func TestHttpResourceURL(t *testing.T) {
+	proxy := ConfigurationApiProxy{ApiURL: DefaultApiURL, AccountName: "account"}
+
+	expected := "https://api.cdnvideo.ru/cdn/api/v1/account/resource/http/42"
+	if url := proxy.httpResourceURL("42"); url != expected {
+		t.Errorf("expected %s, got %s", expected, url)
+	}
+}
+
+// newTokenServer returns a fake api issuing sequential tokens and accepting
+// only the most recently issued one.
+func newTokenServer(t *testing.T, lifetime int) (*httptest.Server, *int32) {
+	var issued int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token/", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"status": 200, "lifetime": %d, "token": "token-%d"}`, lifetime, n)
+	})
+	mux.HandleFunc("/api/account/resource/http/42", func(w http.ResponseWriter, r *http.Request) {
+		expected := fmt.Sprintf("token-%d", atomic.LoadInt32(&issued))
+		if r.Header.Get("cdn-auth-token") != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id": "42"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, &issued
+}
+
+func newTestProxy(t *testing.T, server *httptest.Server) *ConfigurationApiProxy {
+	proxy, err := NewProxy(context.Background(), ProxyConfig{
+		Username:    "user",
+		Password:    "pass",
+		AccountName: "account",
+		ApiURL:      server.URL + "/api",
+		OauthURL:    server.URL + "/oauth/token/",
+	})
+	if err != nil {
+		t.Fatalf("NewProxy: %s", err)
+	}
+
+	return proxy
+}
+
+// authenticate makes a first request so the proxy obtains a token.
+func authenticate(t *testing.T, proxy *ConfigurationApiProxy) {
+	if _, err := proxy.GetHttpResource(context.Background(), "42"); err != nil {
+		t.Fatalf("GetHttpResource: %s", err)
+	}
+}
+
+func TestMakeRequestRefreshesExpiredToken(t *testing.T) {
+	server, issued := newTokenServer(t, 3600)
+	proxy := newTestProxy(t, server)
+	authenticate(t, proxy)
+
+	proxy.Auth.ExpiresAt = time.Now().Add(-time.Second)
+
+	if _, err := proxy.GetHttpResource(context.Background(), "42"); err != nil {
+		t.Fatalf("GetHttpResource: %s", err)
+	}
+	if n := atomic.LoadInt32(issued); n != 2 {
+		t.Errorf("expected 2 token requests, got %d", n)
+	}
+	if !proxy.Auth.ExpiresAt.After(time.Now()) {
+		t.Errorf("expected expiry in the future, got %s", proxy.Auth.ExpiresAt)
+	}
+}
+
+func TestMakeRequestReauthenticatesOnUnauthorized(t *testing.T) {
+	server, issued := newTokenServer(t, 3600)
+	proxy := newTestProxy(t, server)
+	authenticate(t, proxy)
+
+	// Token revoked on the server side while still valid locally
+	proxy.Auth.Token = "revoked"
+
+	if _, err := proxy.GetHttpResource(context.Background(), "42"); err != nil {
+		t.Fatalf("GetHttpResource: %s", err)
+	}
+	if n := atomic.LoadInt32(issued); n != 2 {
+		t.Errorf("expected 2 token requests, got %d", n)
+	}
+}
+
+func TestMakeRequestReplaysBodyOnUnauthorized(t *testing.T) {
+	var issued int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token/", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"status": 200, "lifetime": 3600, "token": "token-%d"}`, n)
+	})
+	mux.HandleFunc("/api/account/resource/http/42", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("cdn-auth-token") != "token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"name":"test"`) {
+			t.Errorf("unexpected replayed body: %s", body)
+		}
+		w.Write([]byte(`{"status": "accept"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	proxy := newTestProxy(t, server)
+
+	if _, err := proxy.UpdateHttpResource(context.Background(), CdnHttpResource{Name: "test"}, "42"); err != nil {
+		t.Fatalf("UpdateHttpResource: %s", err)
+	}
+}
+
+func TestMakeRequestSingleRefreshForConcurrentCalls(t *testing.T) {
+	server, issued := newTokenServer(t, 3600)
+	proxy := newTestProxy(t, server)
+	authenticate(t, proxy)
+
+	proxy.Auth.ExpiresAt = time.Now().Add(-time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := proxy.GetHttpResource(context.Background(), "42"); err != nil {
+				t.Errorf("GetHttpResource: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(issued); n != 2 {
+		t.Errorf("expected 2 token requests, got %d", n)
+	}
+}
+
+func TestNewProxyAuthenticatesLazily(t *testing.T) {
+	server, issued := newTokenServer(t, 3600)
+	proxy := newTestProxy(t, server)
+
+	if n := atomic.LoadInt32(issued); n != 0 {
+		t.Errorf("expected no token requests before the first call, got %d", n)
+	}
+
+	authenticate(t, proxy)
+	authenticate(t, proxy)
+	if n := atomic.LoadInt32(issued); n != 1 {
+		t.Errorf("expected 1 token request, got %d", n)
+	}
+}
+
+func TestNewProxyWithoutCredentials(t *testing.T) {
+	proxy, err := NewProxy(context.Background(), ProxyConfig{AccountName: "account"})
+	if err != nil {
+		t.Fatalf("NewProxy: %s", err)
+	}
+
+	if _, err := proxy.GetHttpResources(context.Background()); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestNewProxyWithoutAccountName(t *testing.T) {
+	proxy, err := NewProxy(context.Background(), ProxyConfig{Token: "token"})
+	if err != nil {
+		t.Fatalf("NewProxy: %s", err)
+	}
+
+	if _, err := proxy.GetHttpResources(context.Background()); !errors.Is(err, ErrNoAccountName) {
+		t.Errorf("expected ErrNoAccountName, got %v", err)
+	}
+}
+
+func TestNewProxyInvalidURL(t *testing.T) {
+	if _, err := NewProxy(context.Background(), ProxyConfig{ApiURL: "api.example.com"}); err == nil {
+		t.Error("expected error for url without scheme")
+	}
+	if _, err := NewProxy(context.Background(), ProxyConfig{OauthURL: "ftp://example.com/token"}); err == nil {
+		t.Error("expected error for non http url")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Now()
+
+	if expiry := tokenExpiry(now, 0); !expiry.IsZero() {
+		t.Errorf("expected zero expiry for unknown lifetime, got %s", expiry)
+	}
+	if expiry := tokenExpiry(now, 3600); !expiry.Equal(now.Add(3600*time.Second - tokenRefreshMargin)) {
+		t.Errorf("unexpected expiry for long lifetime: %s", expiry)
+	}
+	if expiry := tokenExpiry(now, 10); !expiry.Equal(now.Add(5 * time.Second)) {
+		t.Errorf("unexpected expiry for short lifetime: %s", expiry)
+	}
+}
+
+func TestMakeRequestCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	proxy := &ConfigurationApiProxy{HTTPClient: server.Client(), ApiURL: server.URL, AccountName: "account", Auth: AuthStruct{Token: "token"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := proxy.GetHttpResources(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request was not aborted, took %s", elapsed)
+	}
+}
+
+func TestNewProxyStaticToken(t *testing.T) {
+	var unauthorized int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token/", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("static token must not be exchanged")
+	})
+	mux.HandleFunc("/api/account/resource/http/42", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("cdn-auth-token") != "static-token" {
+			t.Errorf("unexpected token: %q", r.Header.Get("cdn-auth-token"))
+		}
+		if atomic.LoadInt32(&unauthorized) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id": "42"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	proxy, err := NewProxy(context.Background(), ProxyConfig{
+		Token:       "static-token",
+		AccountName: "account",
+		ApiURL:      server.URL + "/api",
+		OauthURL:    server.URL + "/oauth/token/",
+	})
+	if err != nil {
+		t.Fatalf("NewProxy: %s", err)
+	}
+
+	if _, err := proxy.GetHttpResource(context.Background(), "42"); err != nil {
+		t.Fatalf("GetHttpResource: %s", err)
+	}
+
+	// A rejected static token is reported instead of re-authenticating
+	atomic.StoreInt32(&unauthorized, 1)
+	if _, err := proxy.GetHttpResource(context.Background(), "42"); !IsUnauthorized(err) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package configuration
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryConfig controls how failed requests to the api are retried.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Jitter randomizes each backoff between half and the full delay.
+	Jitter bool
+}
+
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts: 4,
+		BaseBackoff: 1 * time.Second,
+		MaxBackoff:  30 * time.Second,
+		Jitter:      true,
+	}
+}
+
+// shouldRetry reports whether a request may be sent again after the given
+// response status or transport error. Rate limiting means the request was
+// not processed, so it is retried for any method; other transient failures
+// are retried for idempotent methods only.
+func shouldRetry(method string, status int, err error) bool {
+	if err != nil {
+		return isIdempotent(method)
+	}
+
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(method)
+	}
+
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry (starting from 1).
+// A Retry-After header sent by the api takes precedence, up to MaxBackoff so
+// that a bad header cannot stall requests for longer.
+func (config RetryConfig) backoff(retry int, header http.Header) time.Duration {
+	if delay, ok := parseRetryAfter(header.Get("Retry-After"), time.Now()); ok {
+		return min(delay, config.MaxBackoff)
+	}
+
+	delay := config.MaxBackoff
+	if shift := retry - 1; shift < 32 && config.BaseBackoff<<shift > 0 && config.BaseBackoff<<shift < config.MaxBackoff {
+		delay = config.BaseBackoff << shift
+	}
+
+	if config.Jitter && delay > 1 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds > math.MaxInt64/int(time.Second) {
+			return math.MaxInt64, true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// sleep waits for the given delay or until the context is done.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
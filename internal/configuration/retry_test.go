@@ -0,0 +1,163 @@
+package configuration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer returns a fake api that responds with the given statuses in
+// order and with 200 afterwards, counting the requests it receives.
+func newFlakyServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n <= len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte(`{"status": "accept", "resource_id": "42"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func newRetryProxy(server *httptest.Server, max_attempts int) *ConfigurationApiProxy {
+	return &ConfigurationApiProxy{
+		HTTPClient:  server.Client(),
+		AccountName: "account",
+		ApiURL:      server.URL,
+		Auth:        AuthStruct{Token: "token"},
+		Retry: RetryConfig{
+			MaxAttempts: max_attempts,
+			BaseBackoff: time.Millisecond,
+			MaxBackoff:  10 * time.Millisecond,
+			Jitter:      true,
+		},
+	}
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	server, requests := newFlakyServer(t, nil, http.StatusBadGateway, http.StatusServiceUnavailable)
+	proxy := newRetryProxy(server, 3)
+
+	if _, err := proxy.GetHttpResource(context.Background(), "42"); err != nil {
+		t.Fatalf("GetHttpResource: %s", err)
+	}
+	if n := atomic.LoadInt32(requests); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	server, requests := newFlakyServer(t, nil, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	proxy := newRetryProxy(server, 2)
+
+	if _, err := proxy.GetHttpResource(context.Background(), "42"); err == nil {
+		t.Fatal("expected error after exhausting attempts")
+	}
+	if n := atomic.LoadInt32(requests); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestRetryNonIdempotentOnlyWhenRateLimited(t *testing.T) {
+	server, requests := newFlakyServer(t, nil, http.StatusServiceUnavailable)
+	proxy := newRetryProxy(server, 3)
+
+	if _, err := proxy.CreateHttpResource(context.Background(), CdnHttpResource{Name: "test"}); err == nil {
+		t.Fatal("expected POST not to be retried on 503")
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+
+	server, requests = newFlakyServer(t, http.Header{"Retry-After": []string{"0"}}, http.StatusTooManyRequests)
+	proxy = newRetryProxy(server, 3)
+
+	response, err := proxy.CreateHttpResource(context.Background(), CdnHttpResource{Name: "test"})
+	if err != nil {
+		t.Fatalf("CreateHttpResource: %s", err)
+	}
+	if response.ResourceId != "42" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+	if n := atomic.LoadInt32(requests); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestRetryNotOnClientErrors(t *testing.T) {
+	server, requests := newFlakyServer(t, nil, http.StatusBadRequest)
+	proxy := newRetryProxy(server, 3)
+
+	if _, err := proxy.GetHttpResource(context.Background(), "42"); err == nil {
+		t.Fatal("expected error on 400")
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	config := RetryConfig{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, delay := range expected {
+		if got := config.backoff(i+1, nil); got != delay {
+			t.Errorf("retry %d: expected %s, got %s", i+1, delay, got)
+		}
+	}
+
+	if got := config.backoff(100, nil); got != 5*time.Second {
+		t.Errorf("expected backoff to stay at max for large retries, got %s", got)
+	}
+
+	config.Jitter = true
+	for i := 1; i <= 5; i++ {
+		got := config.backoff(i, nil)
+		if got < expected[i-1]/2 || got > expected[i-1] {
+			t.Errorf("retry %d: jittered backoff %s out of range", i, got)
+		}
+	}
+
+	if got := config.backoff(1, http.Header{"Retry-After": []string{"3"}}); got != 3*time.Second {
+		t.Errorf("expected Retry-After to take precedence, got %s", got)
+	}
+	for _, retry_after := range []string{"7", "99999999999", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)} {
+		if got := config.backoff(1, http.Header{"Retry-After": []string{retry_after}}); got != 5*time.Second {
+			t.Errorf("expected Retry-After %s to be capped by max backoff, got %s", retry_after, got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"Mon, 01 Jan 2024 00:00:10 GMT", 10 * time.Second, true},
+		{"Sun, 31 Dec 2023 23:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, c := range cases {
+		delay, ok := parseRetryAfter(c.value, now)
+		if delay != c.expected || ok != c.ok {
+			t.Errorf("%q: expected (%s, %t), got (%s, %t)", c.value, c.expected, c.ok, delay, ok)
+		}
+	}
+}
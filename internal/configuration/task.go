@@ -0,0 +1,126 @@
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Task is an asynchronous configuration task started by a modifying request.
+type Task struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+	Description string `json:"description"`
+}
+
+// Statuses a task can report. The task endpoint is not described in the
+// published api documentation, so these lists are not taken from a contract:
+// the pending ones start with the "accept" status modifying requests answer
+// with, the others are the usual names for running and finished tasks.
+// WaitForTask logs any other status when it first sees it.
+var (
+	taskPendingStatuses   = []string{"accept", "accepted", "new", "pending", "queued", "in_progress", "processing", "running"}
+	taskSucceededStatuses = []string{"done", "success", "completed"}
+	taskFailedStatuses    = []string{"error", "failed", "reject", "rejected", "canceled"}
+)
+
+// Pending reports whether the task is known to be still running.
+func (task Task) Pending() bool {
+	return hasStatus(task.Status, taskPendingStatuses)
+}
+
+// Succeeded reports whether the task finished and the configuration is deployed.
+func (task Task) Succeeded() bool {
+	return hasStatus(task.Status, taskSucceededStatuses)
+}
+
+// Failed reports whether the task finished without deploying the configuration.
+func (task Task) Failed() bool {
+	return hasStatus(task.Status, taskFailedStatuses)
+}
+
+func hasStatus(status string, statuses []string) bool {
+	for _, s := range statuses {
+		if strings.EqualFold(status, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// taskURL returns the url of a task. Like the statuses it is not documented,
+// it follows the layout of the resource urls.
+func (proxy *ConfigurationApiProxy) taskURL(task_id string) string {
+	return fmt.Sprintf("%s/%s/task/%s", strings.TrimRight(proxy.ApiURL, "/"), proxy.AccountName, task_id)
+}
+
+func (proxy *ConfigurationApiProxy) GetTask(ctx context.Context, task_id string) (*Task, error) {
+	ctx = tflog.SetField(ctx, "cdn_task_id", task_id)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", proxy.taskURL(task_id), nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := proxy.MakeRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	task := Task{}
+	err = json.Unmarshal(body, &task)
+	if err != nil {
+		return nil, err
+	}
+	if task.ID == "" {
+		task.ID = task_id
+	}
+
+	return &task, nil
+}
+
+// WaitForTask polls the task every poll_interval until it reaches a terminal
+// status or ctx is done. A failed task is returned as an APIError. Unknown
+// statuses are polled like pending ones, they are logged as a warning when
+// first seen.
+func (proxy *ConfigurationApiProxy) WaitForTask(ctx context.Context, task_id string, poll_interval time.Duration) (*Task, error) {
+	unknown_statuses := map[string]bool{}
+	for {
+		task, err := proxy.GetTask(ctx, task_id)
+		if err != nil {
+			return nil, err
+		}
+
+		if task.Succeeded() {
+			return task, nil
+		}
+
+		if task.Failed() {
+			return task, &APIError{
+				HTTPStatus:  http.StatusOK,
+				Status:      task.Status,
+				Message:     task.Message,
+				Description: task.Description,
+				TaskId:      task.ID,
+			}
+		}
+
+		if !task.Pending() && !unknown_statuses[task.Status] {
+			unknown_statuses[task.Status] = true
+			tflog.Warn(ctx, "Unknown CDNVideo task status, polling until it changes", map[string]any{
+				"cdn_task_id": task_id,
+				"status":      task.Status,
+				"message":     task.Message,
+			})
+		}
+
+		if err := sleep(ctx, poll_interval); err != nil {
+			return task, fmt.Errorf("task %s is still %q: %w", task_id, task.Status, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package configuration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// newTaskServer returns a fake api reporting the given task statuses in
+// order, repeating the last one afterwards.
+func newTaskServer(t *testing.T, statuses ...string) (*ConfigurationApiProxy, *int32) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/account/task/t-1" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		n := int(atomic.AddInt32(&polls, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		w.Write([]byte(`{"id": "t-1", "status": "` + statuses[n-1] + `", "message": "task message"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	proxy := &ConfigurationApiProxy{HTTPClient: server.Client(), ApiURL: server.URL, AccountName: "account", Auth: AuthStruct{Token: "token"}}
+	return proxy, &polls
+}
+
+func TestWaitForTaskSucceeded(t *testing.T) {
+	proxy, polls := newTaskServer(t, "in_progress", "in_progress", "done")
+
+	task, err := proxy.WaitForTask(context.Background(), "t-1", time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForTask: %s", err)
+	}
+	if !task.Succeeded() {
+		t.Errorf("unexpected task: %+v", task)
+	}
+	if n := atomic.LoadInt32(polls); n != 3 {
+		t.Errorf("expected 3 polls, got %d", n)
+	}
+}
+
+func TestWaitForTaskFailed(t *testing.T) {
+	proxy, _ := newTaskServer(t, "in_progress", "error")
+
+	_, err := proxy.WaitForTask(context.Background(), "t-1", time.Millisecond)
+
+	var api_error *APIError
+	if !errors.As(err, &api_error) {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if api_error.TaskId != "t-1" || api_error.Status != "error" || api_error.Message != "task message" {
+		t.Errorf("unexpected api error: %+v", api_error)
+	}
+}
+
+func TestWaitForTaskUnknownStatus(t *testing.T) {
+	proxy, polls := newTaskServer(t, "in_progress", "deploying", "deploying", "done")
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	if _, err := proxy.WaitForTask(ctx, "t-1", time.Millisecond); err != nil {
+		t.Fatalf("WaitForTask: %s", err)
+	}
+	if n := atomic.LoadInt32(polls); n != 4 {
+		t.Errorf("expected 4 polls, got %d", n)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("decoding log: %s", err)
+	}
+	var warnings []string
+	for _, entry := range entries {
+		if entry["@level"] == "warn" {
+			warnings = append(warnings, fmt.Sprint(entry["status"]))
+		}
+	}
+	if len(warnings) != 1 || warnings[0] != "deploying" {
+		t.Errorf("expected the unknown status to be logged once, got %v", warnings)
+	}
+}
+
+func TestWaitForTaskContextDone(t *testing.T) {
+	proxy, _ := newTaskServer(t, "in_progress")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := proxy.WaitForTask(ctx, "t-1", 5*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -3,11 +3,16 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
+
 	"terraform-provider-cdnvideo/internal/configuration"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -15,16 +20,36 @@ import (
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &httpResource{}
-	_ resource.ResourceWithConfigure = &httpResource{}
+	_ resource.Resource                     = &httpResource{}
+	_ resource.ResourceWithConfigure        = &httpResource{}
+	_ resource.ResourceWithImportState      = &httpResource{}
+	_ resource.ResourceWithConfigValidators = &httpResource{}
+	_ resource.ResourceWithUpgradeState     = &httpResource{}
 )
 
 func NewHTTPResource() resource.Resource {
-	return &httpResource{}
+	return &httpResource{
+		taskPollInterval: defaultTaskPollInterval,
+	}
 }
 
+const (
+	defaultTaskPollInterval = 5 * time.Second
+
+	defaultCreateTimeout = 30 * time.Minute
+	defaultReadTimeout   = 5 * time.Minute
+	defaultUpdateTimeout = 30 * time.Minute
+	defaultDeleteTimeout = 30 * time.Minute
+
+	// deletionModeDeactivate keeps destroyed resources in the account inactive,
+	// deletionModeDelete removes them permanently.
+	deletionModeDeactivate = "deactivate"
+	deletionModeDelete     = "delete"
+)
+
 type httpResource struct {
-	proxy *configuration.ConfigurationApiProxy
+	proxy            *configuration.ConfigurationApiProxy
+	taskPollInterval time.Duration
 }
 
 func (d *httpResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -33,80 +58,171 @@ func (d *httpResource) Metadata(_ context.Context, req resource.MetadataRequest,
 
 // Create a new resource.
 func (resource *httpResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
+	// Retrieve values from plan, values the api computes are not sent
 	var plan CdnHttpResourceModel
-	diag := req.Plan.Get(ctx, &plan)
+	known_plan := tfsdk.Plan{Schema: req.Plan.Schema, Raw: nullUnknownValues(req.Plan.Raw)}
+	diag := known_plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diag...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	create_timeout, diags := plan.Timeouts.Create(ctx, defaultCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, create_timeout)
+	defer cancel()
+
 	// Generate API request from plan
 	http_resource_request, diags := GenerateApiRequest(plan, ctx)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(applyWriteOnlySecrets(ctx, req.Config, &http_resource_request)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Create new cdn http resource
-	response, err := resource.proxy.CreateHttpResource(http_resource_request)
+	// Look for a deactivated resource with the same name to take over
+	var adopted_id string
+	if plan.AdoptExisting.ValueBool() {
+		adopted_id, diags = resource.findAdoptableResource(ctx, plan.Name.ValueString())
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var response *configuration.CdnHttpResourceCreated
+	var err error
+	if adopted_id != "" {
+		// Reactivate the existing resource and replace its configuration
+		ctx = tflog.SetField(ctx, "cdn_resource_id", adopted_id)
+		http_resource_request.Active = plan.Active.ValueBoolPointer()
+		response, err = resource.proxy.UpdateHttpResource(ctx, http_resource_request, adopted_id)
+		if err != nil {
+			resp.Diagnostics.Append(apiErrorDiagnostic(
+				ctx,
+				"Error adopting cdn http resource",
+				fmt.Sprintf("Could not update existing cdn http resource %q, unexpected error: ", adopted_id),
+				err,
+			))
+			return
+		}
+		response.ResourceId = adopted_id
+		tflog.Debug(ctx, "Adopted existing http resource")
+	} else {
+		// Create new cdn http resource
+		response, err = resource.proxy.CreateHttpResource(ctx, http_resource_request)
+		if err != nil {
+			resp.Diagnostics.Append(apiErrorDiagnostic(
+				ctx,
+				"Error creating cdn http resource",
+				"Could not create cdn http resource, unexpected error: ",
+				err,
+			))
+			return
+		}
+		tflog.Debug(ctx, "Created http resource")
+	}
+	// Keep the resource in state should anything below fail, so it is
+	// tainted instead of orphaned
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), response.ResourceId)...)
+
+	err = resource.waitForDeployment(ctx, plan.WaitForDeployment, response.TaskId)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating cdn http resource",
-			"Could not create cdn http resource, unexpected error: "+err.Error(),
-		)
+		resp.Diagnostics.Append(apiErrorDiagnostic(
+			ctx,
+			"Error deploying cdn http resource",
+			"Cdn http resource was created but its configuration was not deployed: ",
+			err,
+		))
 		return
 	}
-	tflog.Debug(ctx, "Created http resource")
 
-	http_resource, err := resource.proxy.GetHttpResource(response.ResourceId)
+	http_resource, err := resource.proxy.GetHttpResource(ctx, response.ResourceId)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(apiErrorDiagnostic(
+			ctx,
 			"Error getting cdn http resource",
-			"Could not getting cdn http resource, unexpected error: "+err.Error(),
-		)
+			"Could not getting cdn http resource, unexpected error: ",
+			err,
+		))
 		return
 	}
 
-	// Map response body to schema and populate Computed attribute values
-	plan.ID = types.StringValue(http_resource.ID)
-	plan.Active = types.BoolPointerValue(http_resource.Active)
-	plan.CreationTs = types.Int64Value(http_resource.CreationTs)
-	plan.CdnDomain = types.StringValue(http_resource.CdnDomain)
+	// Map response body to schema the same way Read does
+	state, diags := GenerateState(http_resource, ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.keepProviderSettings(plan)
 
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, plan)
+	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.State.Raw = reconcileValue(req.Plan.Raw, keepWriteOnlyVersions(req.Plan.Raw, resp.State.Raw))
+	if resp.Private != nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, configuredAttributesKey, recordConfiguredAttributes(ctx, req.Config).encode())...)
+	}
 }
 
 // Read refreshes the Terraform state with the latest data.
 func (resource *httpResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var resource_id string
-	diags := resp.State.GetAttribute(ctx, path.Root("id"), &resource_id)
+	var prior_state CdnHttpResourceModel
+	diags := req.State.Get(ctx, &prior_state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	http_resource, err := resource.proxy.GetHttpResource(resource_id)
+	read_timeout, diags := prior_state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, read_timeout)
+	defer cancel()
+	ctx = tflog.SetField(ctx, "cdn_resource_id", prior_state.ID.ValueString())
+
+	http_resource, err := resource.proxy.GetHttpResource(ctx, prior_state.ID.ValueString())
+	if configuration.IsNotFound(err) {
+		tflog.Warn(ctx, "Cdn http resource not found, removing it from state")
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(apiErrorDiagnostic(
+			ctx,
 			"Unable to Read cdn http resource",
-			err.Error(),
-		)
+			"",
+			err,
+		))
 		return
 	}
 	tflog.Debug(ctx, "Successfully Read cdn http resource")
 
+	// A resource configured as active that was deactivated elsewhere is
+	// treated as deleted when requested, so that it is created again.
+	deactivated := prior_state.Active.ValueBool() && http_resource.Active != nil && !*http_resource.Active
+	if deactivated && prior_state.RemoveDeactivated.ValueBool() {
+		tflog.Warn(ctx, "Cdn http resource was deactivated outside of Terraform, removing it from state")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
 	// Map response body to model
 	state, diags := GenerateState(http_resource, ctx)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	state.keepProviderSettings(prior_state)
 	tflog.Debug(ctx, "Successfully transfer response to model")
 
 	// Set state
@@ -115,53 +231,125 @@ func (resource *httpResource) Read(ctx context.Context, req resource.ReadRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.State.Raw = keepWriteOnlyVersions(req.State.Raw, resp.State.Raw)
 }
 
 func (resource *httpResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan CdnHttpResourceModel
-	diags := req.Plan.Get(ctx, &plan)
+	var plan, prior_state CdnHttpResourceModel
+	known_plan := tfsdk.Plan{Schema: req.Plan.Schema, Raw: nullUnknownValues(req.Plan.Raw)}
+	diags := known_plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &prior_state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	update_timeout, diags := plan.Timeouts.Update(ctx, defaultUpdateTimeout)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Generate API request body from plan
+	ctx, cancel := context.WithTimeout(ctx, update_timeout)
+	defer cancel()
+	ctx = tflog.SetField(ctx, "cdn_resource_id", plan.ID.ValueString())
+
+	// Generate API request bodies from prior state, the resource as it is now
+	// and plan, only the difference between the current resource and the plan
+	// is sent
+	prior_request, diags := GenerateApiRequest(prior_state, ctx)
+	resp.Diagnostics.Append(diags...)
+	current_request, diags := resource.readApiRequest(ctx, req.State, plan.ID.ValueString())
+	resp.Diagnostics.Append(diags...)
 	http_resource_request, diags := GenerateApiRequest(plan, ctx)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(applyWriteOnlySecrets(ctx, req.Config, &http_resource_request)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	_, err := resource.proxy.UpdateHttpResource(http_resource_request, plan.ID.ValueString())
+	// Refuse to overwrite changes made since the plan was made, the api has
+	// no version or etag to make the update conditional
+	changes, err := remoteChanges(prior_request, current_request)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Updating cdn http resource",
-			"Could not update cdn http resource, unexpected error: "+err.Error(),
+			"Could not compare the resource with its prior state, unexpected error: "+err.Error(),
 		)
 		return
 	}
+	if len(changes) > 0 {
+		if !plan.ForceOverwrite.ValueBool() {
+			resp.Diagnostics.Append(conflictDiagnostic(plan.ID.ValueString(), changes))
+			return
+		}
+		tflog.Warn(ctx, "Overwriting changes made outside of Terraform", map[string]any{"changed_fields": len(changes)})
+	}
 
-	http_resource, err := resource.proxy.GetHttpResource(plan.ID.ValueString())
+	patch, err := configuration.NewMergePatch(current_request, http_resource_request)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error getting cdn http resource",
-			"Could not get cdn http resource, unexpected error: "+err.Error(),
+			"Error Updating cdn http resource",
+			"Could not compute the changes to send, unexpected error: "+err.Error(),
 		)
 		return
 	}
 
+	if len(patch) == 0 {
+		tflog.Debug(ctx, "No api fields changed, skipping update request")
+	} else {
+		response, err := resource.proxy.PatchHttpResource(ctx, patch, plan.ID.ValueString())
+		if err != nil {
+			resp.Diagnostics.Append(apiErrorDiagnostic(
+				ctx,
+				"Error Updating cdn http resource",
+				"Could not update cdn http resource, unexpected error: ",
+				err,
+			))
+			return
+		}
+
+		err = resource.waitForDeployment(ctx, plan.WaitForDeployment, response.TaskId)
+		if err != nil {
+			resp.Diagnostics.Append(apiErrorDiagnostic(
+				ctx,
+				"Error deploying cdn http resource",
+				"Cdn http resource was updated but its configuration was not deployed: ",
+				err,
+			))
+			return
+		}
+	}
+
+	http_resource, err := resource.proxy.GetHttpResource(ctx, plan.ID.ValueString())
+	if err != nil {
+		resp.Diagnostics.Append(apiErrorDiagnostic(
+			ctx,
+			"Error getting cdn http resource",
+			"Could not get cdn http resource, unexpected error: ",
+			err,
+		))
+		return
+	}
+
 	// Update resource state
 	state, diags := GenerateState(http_resource, ctx)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	state.keepProviderSettings(plan)
 
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.State.Raw = reconcileValue(req.Plan.Raw, keepWriteOnlyVersions(req.Plan.Raw, resp.State.Raw))
+	if resp.Private != nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, configuredAttributesKey, recordConfiguredAttributes(ctx, req.Config).encode())...)
+	}
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -172,16 +360,204 @@ func (resource *httpResource) Delete(ctx context.Context, req resource.DeleteReq
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	err := resource.proxy.DeactivateHttpResource(state.ID.ValueString())
+
+	if state.DeletionProtection.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("deletion_protection"),
+			"Cdn http resource is protected from deletion",
+			fmt.Sprintf("Cdn http resource %q has deletion_protection enabled. Set deletion_protection to false and apply the change before destroying or replacing it.", state.ID.ValueString()),
+		)
+		return
+	}
+
+	delete_timeout, diags := state.Timeouts.Delete(ctx, defaultDeleteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, delete_timeout)
+	defer cancel()
+	ctx = tflog.SetField(ctx, "cdn_resource_id", state.ID.ValueString())
+
+	var response *configuration.CdnHttpResourceCreated
+	var err error
+	if state.DeletionMode.ValueString() == deletionModeDelete {
+		response, err = resource.proxy.DeleteHttpResource(ctx, state.ID.ValueString())
+	} else {
+		response, err = resource.proxy.DeactivateHttpResource(ctx, state.ID.ValueString())
+	}
+	if configuration.IsNotFound(err) {
+		tflog.Warn(ctx, "Cdn http resource is already gone")
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(
+		resp.Diagnostics.Append(apiErrorDiagnostic(
+			ctx,
 			"Error Deleting cdn http resource",
-			"Could not delete cdn http resource, unexpected error: "+err.Error(),
-		)
+			"Could not delete cdn http resource, unexpected error: ",
+			err,
+		))
+		return
+	}
+
+	err = resource.waitForDeployment(ctx, state.WaitForDeployment, response.TaskId)
+	if err != nil {
+		resp.Diagnostics.Append(apiErrorDiagnostic(
+			ctx,
+			"Error deploying cdn http resource",
+			"Cdn http resource was deleted but the change was not deployed: ",
+			err,
+		))
 		return
 	}
 }
 
+// ImportState populates the state of an existing resource from the api.
+// The resource is identified by its id, or by "name:<name>" or
+// "domain:<cdn_domain>" which must match exactly one resource.
+func (resource *httpResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	resource_id, diags := resource.resolveImportID(ctx, req.ID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx = tflog.SetField(ctx, "cdn_resource_id", resource_id)
+
+	http_resource, err := resource.proxy.GetHttpResource(ctx, resource_id)
+	if err != nil {
+		resp.Diagnostics.Append(apiErrorDiagnostic(
+			ctx,
+			"Unable to import cdn http resource",
+			fmt.Sprintf("Could not read cdn http resource %q: ", resource_id),
+			err,
+		))
+		return
+	}
+
+	state, diags := GenerateState(http_resource, ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.importProviderSettings()
+	tflog.Debug(ctx, "Imported cdn http resource")
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// resolveImportID returns the id of the resource referenced by an import
+// identifier, looking it up by name or cdn domain when prefixed accordingly.
+func (resource *httpResource) resolveImportID(ctx context.Context, import_id string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	kind, value, found := strings.Cut(import_id, ":")
+	if !found || (kind != "name" && kind != "domain") {
+		kind, value = "id", import_id
+	}
+	if value == "" {
+		diags.AddError(
+			"Invalid import identifier",
+			fmt.Sprintf("Expected <id>, name:<name> or domain:<cdn_domain>, got %q", import_id),
+		)
+		return "", diags
+	}
+	if kind == "id" {
+		return value, diags
+	}
+
+	http_resources, err := resource.proxy.GetHttpResources(ctx)
+	if err != nil {
+		diags.Append(apiErrorDiagnostic(
+			ctx,
+			"Unable to import cdn http resource",
+			"Could not list cdn http resources: ",
+			err,
+		))
+		return "", diags
+	}
+
+	var matches []string
+	for _, http_resource := range http_resources {
+		if (kind == "name" && http_resource.Name == value) || (kind == "domain" && http_resource.CdnDomain == value) {
+			matches = append(matches, http_resource.ID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		diags.AddError(
+			"Cdn http resource not found",
+			fmt.Sprintf("No cdn http resource has %s %q", kind, value),
+		)
+	case 1:
+		return matches[0], diags
+	default:
+		diags.AddError(
+			"Multiple cdn http resources found",
+			fmt.Sprintf("%d cdn http resources have %s %q: %s. Import one of them by id instead.", len(matches), kind, value, strings.Join(matches, ", ")),
+		)
+	}
+
+	return "", diags
+}
+
+// findAdoptableResource returns the id of the deactivated resource with the
+// given name, or an empty string if there is none. Active resources are never
+// adopted as they may be in use or managed elsewhere.
+func (resource *httpResource) findAdoptableResource(ctx context.Context, name string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	http_resources, err := resource.proxy.GetHttpResources(ctx)
+	if err != nil {
+		diags.Append(apiErrorDiagnostic(
+			ctx,
+			"Error adopting cdn http resource",
+			"Could not list cdn http resources: ",
+			err,
+		))
+		return "", diags
+	}
+
+	var matches []string
+	for _, http_resource := range http_resources {
+		if http_resource.Name == name && http_resource.Active != nil && !*http_resource.Active {
+			matches = append(matches, http_resource.ID)
+		}
+	}
+
+	if len(matches) > 1 {
+		diags.AddAttributeError(
+			path.Root("adopt_existing"),
+			"Multiple cdn http resources to adopt",
+			fmt.Sprintf("%d deactivated cdn http resources have name %q: %s. Import one of them by id instead.", len(matches), name, strings.Join(matches, ", ")),
+		)
+		return "", diags
+	}
+	if len(matches) == 0 {
+		tflog.Debug(ctx, "No deactivated http resource to adopt, creating a new one", map[string]any{"name": name})
+		return "", diags
+	}
+
+	return matches[0], diags
+}
+
+// waitForDeployment blocks until the configuration task finishes unless
+// waiting is disabled on the resource. Null means the default, which is to wait.
+func (resource *httpResource) waitForDeployment(ctx context.Context, wait types.Bool, task_id string) error {
+	if (!wait.IsNull() && !wait.ValueBool()) || task_id == "" {
+		return nil
+	}
+
+	tflog.Debug(ctx, "Waiting for configuration task", map[string]any{"task_id": task_id})
+	_, err := resource.proxy.WaitForTask(ctx, task_id, resource.taskPollInterval)
+	return err
+}
+
 // Configure adds the provider configured client to the resource.
 func (resource *httpResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
@@ -201,10 +577,33 @@ func (resource *httpResource) Configure(_ context.Context, req resource.Configur
 	resource.proxy = proxy
 }
 
+// keepProviderSettings copies attributes that only control provider behaviour
+// and are not stored in the api from the plan or prior state.
+func (state *CdnHttpResourceModel) keepProviderSettings(from CdnHttpResourceModel) {
+	state.WaitForDeployment = from.WaitForDeployment
+	state.RemoveDeactivated = from.RemoveDeactivated
+	state.DeletionMode = from.DeletionMode
+	state.DeletionProtection = from.DeletionProtection
+	state.AdoptExisting = from.AdoptExisting
+	state.ForceOverwrite = from.ForceOverwrite
+	state.Timeouts = from.Timeouts
+}
+
+// importProviderSettings sets provider-only attributes of an imported resource
+// to their defaults, as there is no configuration to take them from yet.
+func (state *CdnHttpResourceModel) importProviderSettings() {
+	state.WaitForDeployment = types.BoolValue(true)
+	state.RemoveDeactivated = types.BoolValue(false)
+	state.DeletionMode = types.StringValue(deletionModeDeactivate)
+	state.DeletionProtection = types.BoolValue(false)
+	state.AdoptExisting = types.BoolValue(false)
+	state.ForceOverwrite = types.BoolValue(false)
+}
+
 func GenerateState(http_resource configuration.CdnHttpResource, ctx context.Context) (CdnHttpResourceModel, diag.Diagnostics) {
 	servers, all_diags := types.MapValueFrom(ctx, ServersModel{}.AttributeTypes(), http_resource.Origin.Servers)
 
-	locations, diags := types.MapValueFrom(ctx, LocationsModel{}.AttributeTypes(), http_resource.Locations)
+	locations, diags := types.ListValueFrom(ctx, LocationModel{}.AttributeTypes(), http_resource.Locations)
 	all_diags.Append(diags...)
 
 	aws, diags := types.ObjectValueFrom(ctx, AWSModel{}.AttributeTypes(), http_resource.Origin.AWS)
@@ -248,9 +647,9 @@ func GenerateState(http_resource configuration.CdnHttpResource, ctx context.Cont
 			Hostname:       types.StringPointerValue(http_resource.Origin.Hostname),
 			SNIHostname:    types.StringPointerValue(http_resource.Origin.SNIHostname),
 			HTTPS:          types.BoolPointerValue(http_resource.Origin.HTTPS),
-			ReadTimeout:    types.StringPointerValue(http_resource.Origin.ReadTimeout),
-			SendTimeout:    types.StringPointerValue(http_resource.Origin.SendTimeout),
-			ConnectTimeout: types.StringPointerValue(http_resource.Origin.ConnectTimeout),
+			ReadTimeout:    NewDurationPointerValue(http_resource.Origin.ReadTimeout),
+			SendTimeout:    NewDurationPointerValue(http_resource.Origin.SendTimeout),
+			ConnectTimeout: NewDurationPointerValue(http_resource.Origin.ConnectTimeout),
 			AWS:            aws,
 			S3Bucket:       types.StringPointerValue(http_resource.Origin.S3Bucket),
 			SSLVerify:      types.BoolPointerValue(http_resource.Origin.SSLVerify),
@@ -275,7 +674,14 @@ func GenerateState(http_resource configuration.CdnHttpResource, ctx context.Cont
 		Limitations:        limitations,
 		IOSS:               types.BoolPointerValue(http_resource.IOSS),
 		Packaging:          packaging,
-		Locations:          locations,
+		Location:           locations,
+		WaitForDeployment:  types.BoolNull(),
+		RemoveDeactivated:  types.BoolNull(),
+		DeletionMode:       types.StringNull(),
+		DeletionProtection: types.BoolNull(),
+		AdoptExisting:      types.BoolNull(),
+		ForceOverwrite:     types.BoolNull(),
+		Timeouts:           timeouts.Value{Object: types.ObjectNull(TimeoutsModel{}.AttributeTypes())},
 	}
 
 	return state, all_diags
@@ -324,8 +730,8 @@ func GenerateApiRequest(plan CdnHttpResourceModel, ctx context.Context) (configu
 	diags = plan.Limitations.As(ctx, &limitations, opts)
 	all_diags.Append(diags...)
 
-	locations := make(map[string]configuration.Locations)
-	diags = plan.Locations.ElementsAs(ctx, &locations, false)
+	var locations configuration.LocationList
+	diags = plan.Location.ElementsAs(ctx, &locations, false)
 	all_diags.Append(diags...)
 
 	var packaging *configuration.Packaging = new(configuration.Packaging)
@@ -2,14 +2,24 @@ package provider
 
 import (
 	"context"
+	"fmt"
 
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -17,46 +27,53 @@ import (
 
 // TODO: make separate module with all schemas. Divide schemas by modules
 type CdnHttpResourceModel struct {
-	ID                 types.String `tfsdk:"id"`
-	Name               types.String `tfsdk:"name"`
-	CreationTs         types.Int64  `tfsdk:"creation_ts"`
-	CdnDomain          types.String `tfsdk:"cdn_domain"`
-	Active             types.Bool   `tfsdk:"active"`
-	Origin             OriginModel  `tfsdk:"origin"`
-	Cache              types.Object `tfsdk:"cache"`
-	Certificate        types.Int64  `tfsdk:"certificate"`
-	Tuning             types.String `tfsdk:"tuning"`
-	SliceSizeMegabytes types.Int64  `tfsdk:"slice_size_megabytes"`
-	ModernTlsOnly      types.Bool   `tfsdk:"modern_tls_only"`
-	StrongSslCiphers   types.Bool   `tfsdk:"strong_ssl_ciphers"`
-	FollowRedirects    types.Bool   `tfsdk:"follow_redirects"`
-	NoHttp2            types.Bool   `tfsdk:"no_http2"`
-	Http2Https         types.Bool   `tfsdk:"http2https"`
-	HttpsOnly          types.Bool   `tfsdk:"https_only"`
-	UseHttp3           types.Bool   `tfsdk:"use_http3"`
-	Compress           types.Object `tfsdk:"compress"`
-	Robots             types.Object `tfsdk:"robots"`
-	Auth               types.Object `tfsdk:"auth"`
-	Headers            types.Object `tfsdk:"headers"`
-	Cors               types.Object `tfsdk:"cors"`
-	Names              types.Set    `tfsdk:"names"`
-	Limitations        types.Object `tfsdk:"limitations"`
-	IOSS               types.Bool   `tfsdk:"ioss"`
-	Packaging          types.Object `tfsdk:"packaging"`
-	Locations          types.Map    `tfsdk:"locations"`
+	ID                 types.String   `tfsdk:"id"`
+	Name               types.String   `tfsdk:"name"`
+	CreationTs         types.Int64    `tfsdk:"creation_ts"`
+	CdnDomain          types.String   `tfsdk:"cdn_domain"`
+	Active             types.Bool     `tfsdk:"active"`
+	Origin             OriginModel    `tfsdk:"origin"`
+	Cache              types.Object   `tfsdk:"cache"`
+	Certificate        types.Int64    `tfsdk:"certificate"`
+	Tuning             types.String   `tfsdk:"tuning"`
+	SliceSizeMegabytes types.Int64    `tfsdk:"slice_size_megabytes"`
+	ModernTlsOnly      types.Bool     `tfsdk:"modern_tls_only"`
+	StrongSslCiphers   types.Bool     `tfsdk:"strong_ssl_ciphers"`
+	FollowRedirects    types.Bool     `tfsdk:"follow_redirects"`
+	NoHttp2            types.Bool     `tfsdk:"no_http2"`
+	Http2Https         types.Bool     `tfsdk:"http2https"`
+	HttpsOnly          types.Bool     `tfsdk:"https_only"`
+	UseHttp3           types.Bool     `tfsdk:"use_http3"`
+	Compress           types.Object   `tfsdk:"compress"`
+	Robots             types.Object   `tfsdk:"robots"`
+	Auth               types.Object   `tfsdk:"auth"`
+	Headers            types.Object   `tfsdk:"headers"`
+	Cors               types.Object   `tfsdk:"cors"`
+	Names              types.Set      `tfsdk:"names"`
+	Limitations        types.Object   `tfsdk:"limitations"`
+	IOSS               types.Bool     `tfsdk:"ioss"`
+	Packaging          types.Object   `tfsdk:"packaging"`
+	Location           types.List     `tfsdk:"location"`
+	WaitForDeployment  types.Bool     `tfsdk:"wait_for_deployment"`
+	RemoveDeactivated  types.Bool     `tfsdk:"remove_deactivated"`
+	DeletionMode       types.String   `tfsdk:"deletion_mode"`
+	DeletionProtection types.Bool     `tfsdk:"deletion_protection"`
+	AdoptExisting      types.Bool     `tfsdk:"adopt_existing"`
+	ForceOverwrite     types.Bool     `tfsdk:"force_overwrite"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
 }
 
 type OriginModel struct {
-	Servers        types.Map    `tfsdk:"servers"`
-	Hostname       types.String `tfsdk:"hostname"`
-	HTTPS          types.Bool   `tfsdk:"https"`
-	SNIHostname    types.String `tfsdk:"sni_hostname"`
-	ReadTimeout    types.String `tfsdk:"read_timeout"`
-	SendTimeout    types.String `tfsdk:"send_timeout"`
-	ConnectTimeout types.String `tfsdk:"connect_timeout"`
-	AWS            types.Object `tfsdk:"aws"`
-	S3Bucket       types.String `tfsdk:"s3_bucket"`
-	SSLVerify      types.Bool   `tfsdk:"ssl_verify"`
+	Servers        types.Map     `tfsdk:"servers"`
+	Hostname       types.String  `tfsdk:"hostname"`
+	HTTPS          types.Bool    `tfsdk:"https"`
+	SNIHostname    types.String  `tfsdk:"sni_hostname"`
+	ReadTimeout    DurationValue `tfsdk:"read_timeout"`
+	SendTimeout    DurationValue `tfsdk:"send_timeout"`
+	ConnectTimeout DurationValue `tfsdk:"connect_timeout"`
+	AWS            types.Object  `tfsdk:"aws"`
+	S3Bucket       types.String  `tfsdk:"s3_bucket"`
+	SSLVerify      types.Bool    `tfsdk:"ssl_verify"`
 }
 
 func (m OriginModel) AttributeTypes() map[string]attr.Type {
@@ -67,9 +84,9 @@ func (m OriginModel) AttributeTypes() map[string]attr.Type {
 		"hostname":        types.StringType,
 		"https":           types.BoolType,
 		"sni_hostname":    types.StringType,
-		"read_timeout":    types.StringType,
-		"send_timeout":    types.StringType,
-		"connect_timeout": types.StringType,
+		"read_timeout":    DurationType{},
+		"send_timeout":    DurationType{},
+		"connect_timeout": DurationType{},
 		"aws": types.ObjectType{
 			AttrTypes: AWSModel{}.AttributeTypes(),
 		},
@@ -97,8 +114,12 @@ func (m AWSModel) AttributeTypes() map[string]attr.Type {
 	return map[string]attr.Type{
 		"auth": types.ObjectType{
 			AttrTypes: map[string]attr.Type{
-				"access_key": types.StringType,
-				"secret_key": types.StringType,
+				"access_key":            types.StringType,
+				"access_key_wo":         types.StringType,
+				"access_key_wo_version": types.Int64Type,
+				"secret_key":            types.StringType,
+				"secret_key_wo":         types.StringType,
+				"secret_key_wo_version": types.Int64Type,
 			},
 		},
 	}
@@ -119,10 +140,10 @@ func (m CacheModel) AttributeTypes() map[string]attr.Type {
 		},
 		"valid": types.ObjectType{
 			AttrTypes: map[string]attr.Type{
-				"c_2xx": types.StringType,
-				"c_3xx": types.StringType,
-				"c_4xx": types.StringType,
-				"c_5xx": types.StringType,
+				"c_2xx": DurationType{},
+				"c_3xx": DurationType{},
+				"c_4xx": DurationType{},
+				"c_5xx": DurationType{},
 				"force": types.BoolType,
 			},
 		},
@@ -156,9 +177,11 @@ func (m AuthModel) AttributeTypes() map[string]attr.Type {
 		"forbidden": types.BoolType,
 		"md5": types.ObjectType{
 			AttrTypes: map[string]attr.Type{
-				"secret":   types.StringType,
-				"forever":  types.BoolType,
-				"anywhere": types.BoolType,
+				"secret":            types.StringType,
+				"secret_wo":         types.StringType,
+				"secret_wo_version": types.Int64Type,
+				"forever":           types.BoolType,
+				"anywhere":          types.BoolType,
 			},
 		},
 	}
@@ -285,11 +308,13 @@ func (m LimitationsModel) AttributeTypes() map[string]attr.Type {
 	}
 }
 
-type LocationsModel struct{}
+type LocationModel struct{}
 
-func (m LocationsModel) AttributeTypes() attr.Type {
+func (m LocationModel) AttributeTypes() attr.Type {
 	return types.ObjectType{
 		AttrTypes: map[string]attr.Type{
+			"path":  types.StringType,
+			"match": types.StringType,
 			"cache": types.ObjectType{
 				AttrTypes: CacheModel{}.AttributeTypes(),
 			},
@@ -349,9 +374,49 @@ func (m RewriteModel) AttributeTypes() map[string]attr.Type {
 
 type RewriteModel struct{}
 
-func (d *httpResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+type TimeoutsModel struct{}
+
+func (m TimeoutsModel) AttributeTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"create": types.StringType,
+		"read":   types.StringType,
+		"update": types.StringType,
+		"delete": types.StringType,
+	}
+}
+
+// ConfigValidators checks the dependencies between attributes that are only
+// documented in their descriptions.
+func (d *httpResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	in_locations := func(steps ...string) path.Expression {
+		expression := path.MatchRoot("location").AtAnyListIndex()
+		for _, step := range steps {
+			expression = expression.AtName(step)
+		}
+		return expression
+	}
+
+	return []resource.ConfigValidator{
+		requiresSiblingValue(path.MatchRoot("slice_size_megabytes"), "tuning", types.StringValue("large")),
+		requiresSiblingValue(path.MatchRoot("strong_ssl_ciphers"), "modern_tls_only", types.BoolValue(true)).
+			When(types.BoolValue(true)),
+		requiresSiblingValue(path.MatchRoot("robots").AtName("robots_content"), "type", types.StringValue("custom")),
+		requiresSiblingValue(path.MatchRoot("origin").AtName("sni_hostname"), "https", types.BoolValue(true)).
+			In(in_locations("origin", "sni_hostname")),
+		requiresSiblingValue(path.MatchRoot("origin").AtName("ssl_verify"), "https", types.BoolValue(true)).
+			In(in_locations("origin", "ssl_verify")),
+		requiresSiblingValue(path.MatchRoot("cache").AtName("args_whitelist"), "consider_args", types.BoolValue(true)).
+			In(in_locations("cache", "args_whitelist")),
+		requiresSiblingValue(path.MatchRoot("cache").AtName("cookies_whitelist"), "consider_cookies", types.BoolValue(true)).
+			In(in_locations("cache", "cookies_whitelist")),
+		locationRulesValidator{},
+	}
+}
+
+func (d *httpResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	// TODO: Maybe use resource plan modifier
 	resp.Schema = schema.Schema{
+		Version: httpResourceSchemaVersion,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Description: "HTTP resource ID",
@@ -393,38 +458,76 @@ func (d *httpResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 			"tuning": schema.StringAttribute{
 				Description: "Optimization of distribution. One of [default, large, live]",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					useServerDefault(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf("default", "large", "live"),
+				},
 			},
 			"slice_size_megabytes": schema.Int64Attribute{
 				Description: "Slice size in MB (only for tuning=large)",
 				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
 			},
 			"modern_tls_only": schema.BoolAttribute{
 				Description: "Use only modern versions of TLS",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"strong_ssl_ciphers": schema.BoolAttribute{
 				Description: "Use strong SSL ciphers (requires modern_tls_only=true)",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"follow_redirects": schema.BoolAttribute{
 				Description: "Follow redirects",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"no_http2": schema.BoolAttribute{
 				Description: "Disable HTTP2",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"http2https": schema.BoolAttribute{
 				Description: "Automatically redirect HTTP to HTTPS on distribution",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"https_only": schema.BoolAttribute{
 				Description: "Use only HTTPS for distribution",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"use_http3": schema.BoolAttribute{
 				Description: "Use HTTP3",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"compress":    CompressSchema(),
 			"robots":      RobotsSchema(),
@@ -436,13 +539,33 @@ func (d *httpResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 			"ioss": schema.BoolAttribute{
 				Description: "Image Optimization and Modification",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"packaging": PackagingSchema(),
-			"locations": schema.MapNestedAttribute{
-				Description: "Rules for specific request paths",
+			"location": schema.ListNestedAttribute{
+				Description: "Rules for specific request paths, matched like nginx locations: an exact path wins, otherwise the longest matching prefix if it is a priority_prefix one, otherwise the first regular expression matching in list order, otherwise the longest matching prefix",
 				Optional:    true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							Description: "Request path to match, a regular expression in RE2 syntax for the regex match types",
+							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"match": schema.StringAttribute{
+							Description: fmt.Sprintf("How path is matched against the request path. One of [%s, %s, %s, %s, %s]. Defaults to %s", configuration.LocationMatchPrefix, configuration.LocationMatchPriorityPrefix, configuration.LocationMatchExact, configuration.LocationMatchRegex, configuration.LocationMatchRegexCaseInsensitive, configuration.LocationMatchPrefix),
+							Optional:    true,
+							Computed:    true,
+							Default:     stringdefault.StaticString(configuration.LocationMatchPrefix),
+							Validators: []validator.String{
+								stringvalidator.OneOf(configuration.LocationMatchPrefix, configuration.LocationMatchPriorityPrefix, configuration.LocationMatchExact, configuration.LocationMatchRegex, configuration.LocationMatchRegexCaseInsensitive),
+							},
+						},
 						"cache":       CacheSchema(),
 						"origin":      OriginSchema(false, true),
 						"auth":        AuthSchema(),
@@ -459,10 +582,60 @@ func (d *httpResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 						"return_http_status_code": schema.Int64Attribute{
 							Description: "HTTP code to respond instead of content",
 							Optional:    true,
+							Validators: []validator.Int64{
+								int64validator.Between(100, 599),
+							},
 						},
 					},
 				},
 			},
+			"wait_for_deployment": schema.BoolAttribute{
+				Description: "Wait until configuration changes are deployed to the CDN before finishing create, update or delete. Defaults to true",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"remove_deactivated": schema.BoolAttribute{
+				Description: "Remove the resource from state when it was deactivated outside of Terraform, so that it is planned to be created again. Defaults to false",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"deletion_mode": schema.StringAttribute{
+				Description: "What happens to the resource on destroy: deactivate keeps it in the account inactive, delete removes it permanently and frees its name. One of [deactivate, delete]. Defaults to deactivate",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(deletionModeDeactivate),
+				Validators: []validator.String{
+					stringvalidator.OneOf(deletionModeDeactivate, deletionModeDelete),
+				},
+			},
+			"adopt_existing": schema.BoolAttribute{
+				Description: "On create, take over a deactivated resource with the same name instead of creating a new one. It is reactivated and its configuration replaced. Defaults to false",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"force_overwrite": schema.BoolAttribute{
+				Description: "On update, overwrite changes made to the resource outside of Terraform since it was last read instead of failing. Defaults to false",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Description: "Prevent the resource from being destroyed or replaced. Must be set to false and applied before the resource can be deleted. Defaults to false",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
 		},
 	}
 }
@@ -471,14 +644,26 @@ func CacheSchema() schema.Attribute {
 	return schema.SingleNestedAttribute{
 		Description: "Cache settings",
 		Optional:    true,
+		Computed:    true,
+		PlanModifiers: []planmodifier.Object{
+			useServerDefault(),
+		},
 		Attributes: map[string]schema.Attribute{
 			"disable": schema.BoolAttribute{
 				Description: "Do not cache content",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"consider_args": schema.BoolAttribute{
 				Description: "Consider query string in caching",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"args_whitelist": schema.SetAttribute{
 				Description: "List of query string parameters to consider when caching (requires cache.consider_args=true)",
@@ -488,6 +673,10 @@ func CacheSchema() schema.Attribute {
 			"consider_cookies": schema.BoolAttribute{
 				Description: "Consider cookies in caching",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"cookies_whitelist": schema.SetAttribute{
 				Description: "List of cookie to consider when caching (requires cache.consider_cookies=true)",
@@ -497,32 +686,60 @@ func CacheSchema() schema.Attribute {
 			"valid": schema.SingleNestedAttribute{
 				Description: "Cache time settings",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Object{
+					useServerDefault(),
+				},
 				Attributes: map[string]schema.Attribute{
 					"c_2xx": schema.StringAttribute{
-						Description: "Cache time for 2xx codes",
+						Description: "Cache time for 2xx codes, e.g. \"1d\"",
 						Optional:    true,
+						CustomType:  DurationType{},
+						Validators: []validator.String{
+							validDuration(),
+						},
 					},
 					"c_3xx": schema.StringAttribute{
-						Description: "Cache time for 3xx codes",
+						Description: "Cache time for 3xx codes, e.g. \"1d\"",
 						Optional:    true,
+						CustomType:  DurationType{},
+						Validators: []validator.String{
+							validDuration(),
+						},
 					},
 					"c_4xx": schema.StringAttribute{
-						Description: "Cache time for 4xx codes",
+						Description: "Cache time for 4xx codes, e.g. \"1d\"",
 						Optional:    true,
+						CustomType:  DurationType{},
+						Validators: []validator.String{
+							validDuration(),
+						},
 					},
 					"c_5xx": schema.StringAttribute{
-						Description: "Cache time for 5xx codes",
+						Description: "Cache time for 5xx codes, e.g. \"1d\"",
 						Optional:    true,
+						CustomType:  DurationType{},
+						Validators: []validator.String{
+							validDuration(),
+						},
 					},
 					"force": schema.BoolAttribute{
 						Description: "Ignore cache headers",
 						Optional:    true,
+						Computed:    true,
+						PlanModifiers: []planmodifier.Bool{
+							useServerDefault(),
+						},
 					},
 				},
 			},
 			"use_stale": schema.BoolAttribute{
 				Description: "Enables/disables the ability to give outdated cached content if the origin is unavailable",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 		},
 	}
@@ -542,6 +759,9 @@ func OriginSchema(required, optional bool) schema.Attribute {
 						"port": schema.Int64Attribute{
 							Description: "Origin port",
 							Optional:    true,
+							Validators: []validator.Int64{
+								int64validator.Between(1, 65535),
+							},
 						},
 						"weight": schema.Int64Attribute{
 							Description: "Weight for balancing",
@@ -565,22 +785,50 @@ func OriginSchema(required, optional bool) schema.Attribute {
 			"https": schema.BoolAttribute{
 				Description: "Whether to use HTTPS when requesting origin",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"sni_hostname": schema.StringAttribute{
 				Description: "Allows the source to understand which certificate to use for connection if the source server provides multiple certificates (requires origin.https=true)",
 				Optional:    true,
 			},
 			"read_timeout": schema.StringAttribute{
-				Description: "Read timeout in seconds",
+				Description: "Read timeout, e.g. \"10s\"",
 				Optional:    true,
+				Computed:    true,
+				CustomType:  DurationType{},
+				PlanModifiers: []planmodifier.String{
+					useServerDefault(),
+				},
+				Validators: []validator.String{
+					validDuration(),
+				},
 			},
 			"send_timeout": schema.StringAttribute{
-				Description: "Send timeout in seconds",
+				Description: "Send timeout, e.g. \"10s\"",
 				Optional:    true,
+				Computed:    true,
+				CustomType:  DurationType{},
+				PlanModifiers: []planmodifier.String{
+					useServerDefault(),
+				},
+				Validators: []validator.String{
+					validDuration(),
+				},
 			},
 			"connect_timeout": schema.StringAttribute{
-				Description: "Connect timeout in seconds",
+				Description: "Connect timeout, e.g. \"10s\"",
 				Optional:    true,
+				Computed:    true,
+				CustomType:  DurationType{},
+				PlanModifiers: []planmodifier.String{
+					useServerDefault(),
+				},
+				Validators: []validator.String{
+					validDuration(),
+				},
 			},
 			"aws": schema.SingleNestedAttribute{
 				Description: "Parameters for using AWS authorization when requesting origin",
@@ -591,11 +839,25 @@ func OriginSchema(required, optional bool) schema.Attribute {
 						Required:    true,
 						Attributes: map[string]schema.Attribute{
 							"access_key": schema.StringAttribute{
-								Required: true,
+								Description: "Access key, stored in state. Exactly one of access_key and access_key_wo must be set",
+								Optional:    true,
+								Sensitive:   true,
+								Validators: []validator.String{
+									stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("access_key_wo")),
+								},
 							},
+							"access_key_wo":         writeOnlySecretSchema("Access key", "access_key"),
+							"access_key_wo_version": writeOnlySecretVersionSchema("access_key"),
 							"secret_key": schema.StringAttribute{
-								Required: true,
+								Description: "Secret key, stored in state. Exactly one of secret_key and secret_key_wo must be set",
+								Optional:    true,
+								Sensitive:   true,
+								Validators: []validator.String{
+									stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("secret_key_wo")),
+								},
 							},
+							"secret_key_wo":         writeOnlySecretSchema("Secret key", "secret_key"),
+							"secret_key_wo_version": writeOnlySecretVersionSchema("secret_key"),
 						},
 					},
 				},
@@ -607,23 +869,66 @@ func OriginSchema(required, optional bool) schema.Attribute {
 			"ssl_verify": schema.BoolAttribute{
 				Description: "Should check origins certificate (requires origin.https=true)",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 		},
 	}
 }
 
+// writeOnlySecretSchema returns the write-only variant of the secret
+// attribute named name. Its value is sent as name but never stored in state,
+// it requires a version attribute to tell Terraform when to send it again.
+func writeOnlySecretSchema(description, name string) schema.Attribute {
+	return schema.StringAttribute{
+		Description: fmt.Sprintf("%s, not stored in state. Requires Terraform 1.11 or later and %s_wo_version", description, name),
+		Optional:    true,
+		Sensitive:   true,
+		WriteOnly:   true,
+		Validators: []validator.String{
+			stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName(name + "_wo_version")),
+		},
+	}
+}
+
+// writeOnlySecretVersionSchema returns the version attribute of the
+// write-only variant of the secret attribute named name.
+func writeOnlySecretVersionSchema(name string) schema.Attribute {
+	return schema.Int64Attribute{
+		Description: fmt.Sprintf("Version of %[1]s_wo. Changes to %[1]s_wo are not detected since it is not stored, change this value to send it again", name),
+		Optional:    true,
+		Validators: []validator.Int64{
+			int64validator.AlsoRequires(path.MatchRelative().AtParent().AtName(name + "_wo")),
+		},
+	}
+}
+
 func CompressSchema() schema.Attribute {
 	return schema.SingleNestedAttribute{
 		Description: "Compression settings. This service is paid according to the tariffs indicated in dashboard.",
 		Optional:    true,
+		Computed:    true,
+		PlanModifiers: []planmodifier.Object{
+			useServerDefault(),
+		},
 		Attributes: map[string]schema.Attribute{
 			"brotli": schema.BoolAttribute{
 				Description: "Use Brotli compression",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"gzip": schema.BoolAttribute{
 				Description: "Use Gzip compression",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 		},
 	}
@@ -637,6 +942,9 @@ func RobotsSchema() schema.Attribute {
 			"type": schema.StringAttribute{
 				Description: "Type of robots.txt handling. One of [deny, custom, cached]",
 				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("deny", "custom", "cached"),
+				},
 			},
 			"robots_content": schema.StringAttribute{
 				Description: "Text of robots.txt (only for type=custom)",
@@ -656,9 +964,15 @@ func AuthSchema() schema.Attribute {
 				Optional:    true,
 				Attributes: map[string]schema.Attribute{
 					"secret": schema.StringAttribute{
-						Description: "Secret word",
+						Description: "Secret word, stored in state. Conflicts with secret_wo",
 						Optional:    true,
+						Sensitive:   true,
+						Validators: []validator.String{
+							stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("secret_wo")),
+						},
 					},
+					"secret_wo":         writeOnlySecretSchema("Secret word", "secret"),
+					"secret_wo_version": writeOnlySecretVersionSchema("secret"),
 					"forever": schema.BoolAttribute{
 						Description: "No time limit",
 						Optional:    true,
@@ -734,6 +1048,10 @@ func CorsSchema() schema.Attribute {
 			"credentials": schema.BoolAttribute{
 				Description: "Set the Access-Control-Allow-Credentials header",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 			"max_age": schema.Int64Attribute{
 				Description: "Preflight request response lifetime",
@@ -742,6 +1060,10 @@ func CorsSchema() schema.Attribute {
 			"disable": schema.BoolAttribute{
 				Description: "Disable CORS",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.Bool{
+					useServerDefault(),
+				},
 			},
 		},
 	}
@@ -787,6 +1109,9 @@ func LimitationsSchema() schema.Attribute {
 						"default_action": schema.StringAttribute{
 							Description: "Default action. One of [allow, deny]",
 							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("allow", "deny"),
+							},
 						},
 						"exclude": schema.SetNestedAttribute{
 							Description: "Exclusions",
@@ -796,6 +1121,9 @@ func LimitationsSchema() schema.Attribute {
 									"action": schema.StringAttribute{
 										Description: "Action. One of [allow, deny]",
 										Required:    true,
+										Validators: []validator.String{
+											stringvalidator.OneOf("allow", "deny"),
+										},
 									},
 									"country": schema.StringAttribute{
 										Description: "Country code in ISO 3166-1 alpha-2 format",
@@ -820,6 +1148,9 @@ func LimitationsSchema() schema.Attribute {
 						"default_action": schema.StringAttribute{
 							Description: "Default action. One of [allow, deny]",
 							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("allow", "deny"),
+							},
 						},
 						"exclude": schema.SetNestedAttribute{
 							Description: "Exclusions",
@@ -845,6 +1176,9 @@ func LimitationsSchema() schema.Attribute {
 						"default_action": schema.StringAttribute{
 							Description: "Default action. One of [allow, deny]",
 							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("allow", "deny"),
+							},
 						},
 						"exclude": schema.SetNestedAttribute{
 							Description: "Exclusions",
@@ -870,6 +1204,9 @@ func LimitationsSchema() schema.Attribute {
 						"default_action": schema.StringAttribute{
 							Description: "Default action. One of [allow, deny]",
 							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("allow", "deny"),
+							},
 						},
 						"exclude": schema.SetNestedAttribute{
 							Description: "Exclusions",
@@ -904,6 +1241,9 @@ func PackagingSchema() schema.Attribute {
 						Description: "Formats in which videos are planned to be distributed. One of [MPEG-DASH, HLS]",
 						Required:    true,
 						ElementType: types.StringType,
+						Validators: []validator.Set{
+							setvalidator.ValueStringsAre(stringvalidator.OneOf("MPEG-DASH", "HLS")),
+						},
 					},
 				},
 			},
@@ -926,8 +1266,11 @@ func RewriteSchema() schema.Attribute {
 					Optional:    true,
 				},
 				"flag": schema.StringAttribute{
-					Description: "Rewrite option",
+					Description: "Rewrite flag. One of [last, break, redirect, permanent]",
 					Optional:    true,
+					Validators: []validator.String{
+						stringvalidator.OneOf("last", "break", "redirect", "permanent"),
+					},
 				},
 			},
 		},
@@ -67,7 +67,7 @@ func TestResource(t *testing.T) {
 					resource.TestCheckNoResourceAttr(resource_name, "limitations"),
 					resource.TestCheckNoResourceAttr(resource_name, "ioss"),
 					resource.TestCheckNoResourceAttr(resource_name, "packaging"),
-					resource.TestCheckNoResourceAttr(resource_name, "locations"),
+					resource.TestCheckNoResourceAttr(resource_name, "location"),
 				),
 			},
 			// Check full configuration
@@ -250,8 +250,10 @@ func TestResource(t *testing.T) {
 							]
 						}
 					}
-					locations = {
-						"path_to_content" = {
+					location = [
+						{
+							path  = "path_to_content"
+							match = "prefix"
 							cache = {
 								disable = false
 								consider_args = true
@@ -420,7 +422,7 @@ func TestResource(t *testing.T) {
 							]
 							return_http_status_code = 403
 						}
-					}
+					]
 				}`,
 				Check: resource.ComposeAggregateTestCheckFunc(
 					// Check set options
@@ -498,70 +500,72 @@ func TestResource(t *testing.T) {
 					resource.TestCheckResourceAttr(resource_name, "ioss", "false"),
 					resource.TestCheckResourceAttr(resource_name, "packaging.mp4.output_protocols.0", "MPEG-DASH"),
 
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.disable", "false"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.consider_args", "true"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.args_whitelist.0", "param1"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.consider_cookies", "true"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.cookies_whitelist.0", "param1"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.use_stale", "false"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.valid.c_2xx", "1d"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.valid.c_3xx", "1d"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.valid.c_4xx", "1s"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.valid.c_5xx", "1s"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cache.valid.force", "false"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.servers.google.com.port", "443"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.servers.google.com.weight", "1"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.servers.google.com.max_fails", "10"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.servers.google.com.backup", "false"),
-					resource.TestCheckResourceAttrSet(resource_name, "locations.path_to_content.origin.servers.storage.yandexcloud.net.%"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.hostname", "string"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.https", "true"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.sni_hostname", "custom-host.com"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.read_timeout", "10s"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.send_timeout", "10s"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.connect_timeout", "10s"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.aws.auth.access_key", "string"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.aws.auth.secret_key", "string"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.s3_bucket", "string"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.origin.ssl_verify", "false"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.path", "path_to_content"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.match", "prefix"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.disable", "false"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.consider_args", "true"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.args_whitelist.0", "param1"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.consider_cookies", "true"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.cookies_whitelist.0", "param1"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.use_stale", "false"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.valid.c_2xx", "1d"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.valid.c_3xx", "1d"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.valid.c_4xx", "1s"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.valid.c_5xx", "1s"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cache.valid.force", "false"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.servers.google.com.port", "443"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.servers.google.com.weight", "1"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.servers.google.com.max_fails", "10"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.servers.google.com.backup", "false"),
+					resource.TestCheckResourceAttrSet(resource_name, "location.0.origin.servers.storage.yandexcloud.net.%"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.hostname", "string"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.https", "true"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.sni_hostname", "custom-host.com"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.read_timeout", "10s"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.send_timeout", "10s"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.connect_timeout", "10s"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.aws.auth.access_key", "string"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.aws.auth.secret_key", "string"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.s3_bucket", "string"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.origin.ssl_verify", "false"),
 
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.auth.md5.secret", "string"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.auth.md5.forever", "false"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.auth.md5.anywhere", "false"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.headers.request.header_name", "header_value"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.headers.response.header_name", "header_value"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.headers.hide_in_response.0", "header-to-hide"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cors.domains.0", "example.com"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cors.headers.0", "string"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cors.expose.0", "string"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cors.methods.0", "STRING"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cors.credentials", "true"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cors.max_age", "120"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.cors.disable", "false"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.geo.0.default_action", "allow"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.geo.0.exclude.0.action", "deny"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.geo.0.exclude.0.country", "RU"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.geo.0.exclude.0.region", "BEL"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.geo.0.times.0.start", "2024-01-01T00:00:00Z"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.geo.0.times.0.end", "2024-01-02T00:00:00Z"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.ip.0.default_action", "allow"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.ip.0.exclude.0.ip", "192.168.0.1/24"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.ip.0.times.0.start", "2024-01-01T00:00:00Z"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.ip.0.times.0.end", "2024-01-02T00:00:00Z"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.referer.0.default_action", "allow"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.referer.0.exclude.0.referer", "*.ru"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.referer.0.times.0.start", "2024-01-01T00:00:00Z"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.referer.0.times.0.end", "2024-01-02T00:00:00Z"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.useragent.0.default_action", "allow"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.useragent.0.exclude.0.useragent", "browser_name"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.useragent.0.times.0.start", "2024-01-01T00:00:00Z"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.limitations.useragent.0.times.0.end", "2024-01-02T00:00:00Z"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.ioss", "false"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.packaging.mp4.output_protocols.0", "MPEG-DASH"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.rewrite.0.from", "^/cdn/.+(/_video_.+)"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.rewrite.0.to", "$1"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.rewrite.0.flag", "break"),
-					resource.TestCheckResourceAttr(resource_name, "locations.path_to_content.return_http_status_code", "403"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.auth.md5.secret", "string"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.auth.md5.forever", "false"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.auth.md5.anywhere", "false"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.headers.request.header_name", "header_value"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.headers.response.header_name", "header_value"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.headers.hide_in_response.0", "header-to-hide"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cors.domains.0", "example.com"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cors.headers.0", "string"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cors.expose.0", "string"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cors.methods.0", "STRING"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cors.credentials", "true"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cors.max_age", "120"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.cors.disable", "false"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.geo.0.default_action", "allow"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.geo.0.exclude.0.action", "deny"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.geo.0.exclude.0.country", "RU"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.geo.0.exclude.0.region", "BEL"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.geo.0.times.0.start", "2024-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.geo.0.times.0.end", "2024-01-02T00:00:00Z"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.ip.0.default_action", "allow"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.ip.0.exclude.0.ip", "192.168.0.1/24"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.ip.0.times.0.start", "2024-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.ip.0.times.0.end", "2024-01-02T00:00:00Z"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.referer.0.default_action", "allow"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.referer.0.exclude.0.referer", "*.ru"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.referer.0.times.0.start", "2024-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.referer.0.times.0.end", "2024-01-02T00:00:00Z"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.useragent.0.default_action", "allow"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.useragent.0.exclude.0.useragent", "browser_name"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.useragent.0.times.0.start", "2024-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.limitations.useragent.0.times.0.end", "2024-01-02T00:00:00Z"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.ioss", "false"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.packaging.mp4.output_protocols.0", "MPEG-DASH"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.rewrite.0.from", "^/cdn/.+(/_video_.+)"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.rewrite.0.to", "$1"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.rewrite.0.flag", "break"),
+					resource.TestCheckResourceAttr(resource_name, "location.0.return_http_status_code", "403"),
 
 					// Check computed options
 					resource.TestCheckResourceAttrSet(resource_name, "id"),
@@ -570,6 +574,12 @@ func TestResource(t *testing.T) {
 					resource.TestCheckResourceAttrSet(resource_name, "creation_ts"),
 				),
 			},
+			// Check import of the full configuration
+			{
+				ResourceName:      resource_name,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
 			// Check remove full configuration
 			{
 				Config: providerConfig + `
@@ -626,7 +636,7 @@ func TestResource(t *testing.T) {
 					resource.TestCheckNoResourceAttr(resource_name, "limitations"),
 					resource.TestCheckNoResourceAttr(resource_name, "ioss"),
 					resource.TestCheckNoResourceAttr(resource_name, "packaging"),
-					resource.TestCheckNoResourceAttr(resource_name, "locations"),
+					resource.TestCheckNoResourceAttr(resource_name, "location"),
 				),
 			},
 			// Delete testing automatically occurs in TestCase
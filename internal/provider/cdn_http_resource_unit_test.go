@@ -0,0 +1,992 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func testApiResource() configuration.CdnHttpResource {
+	port := 443
+	return configuration.CdnHttpResource{
+		Name: "testname",
+		Origin: &configuration.Origin{
+			Servers: map[string]configuration.Servers{"example.com": {Port: &port}},
+		},
+	}
+}
+
+func testCreatePlan(t *testing.T) CdnHttpResourceModel {
+	plan := testModel(t, testApiResource())
+	plan.ID = types.StringUnknown()
+	plan.CdnDomain = types.StringUnknown()
+	plan.CreationTs = types.Int64Unknown()
+	plan.Active = types.BoolValue(true)
+	return plan
+}
+
+func TestCreateWaitsForDeployment(t *testing.T) {
+	api := newFakeAPI(t)
+	api.taskStatuses = []string{"in_progress", "in_progress", "done"}
+	r := api.resource()
+
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, testCreatePlan(t)), Plan: testPlan(t, testCreatePlan(t))}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create: %v", resp.Diagnostics)
+	}
+
+	if polls := api.taskPolls["task-1"]; polls != 3 {
+		t.Errorf("expected 3 task polls, got %d", polls)
+	}
+	state := stateModel(t, resp.State)
+	if state.ID.ValueString() != "1" || state.CdnDomain.ValueString() != "cdn1.example.net" {
+		t.Errorf("unexpected state: %+v", state)
+	}
+}
+
+func TestCreateWithoutWaitingForDeployment(t *testing.T) {
+	api := newFakeAPI(t)
+	api.taskStatuses = []string{"in_progress"}
+	r := api.resource()
+
+	plan := testCreatePlan(t)
+	plan.WaitForDeployment = types.BoolValue(false)
+
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, plan), Plan: testPlan(t, plan)}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create: %v", resp.Diagnostics)
+	}
+	if polls := api.taskPolls["task-1"]; polls != 0 {
+		t.Errorf("expected no task polls, got %d", polls)
+	}
+}
+
+func TestCreateFailedDeployment(t *testing.T) {
+	api := newFakeAPI(t)
+	api.taskStatuses = []string{"in_progress", "error"}
+	r := api.resource()
+
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, testCreatePlan(t)), Plan: testPlan(t, testCreatePlan(t))}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected Create to fail")
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, "task error") {
+		t.Errorf("expected task message in diagnostic, got %q", detail)
+	}
+
+	// The created resource stays in state to be replaced on the next apply
+	var id types.String
+	resp.State.GetAttribute(context.Background(), path.Root("id"), &id)
+	if id.ValueString() != "1" {
+		t.Errorf("expected id in state, got %s", id)
+	}
+}
+
+func TestCreateFailedRead(t *testing.T) {
+	api := newFakeAPI(t)
+	api.failReads = true
+	r := api.resource()
+
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, testCreatePlan(t)), Plan: testPlan(t, testCreatePlan(t))}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected Create to fail")
+	}
+
+	var id types.String
+	resp.State.GetAttribute(context.Background(), path.Root("id"), &id)
+	if id.ValueString() != "1" {
+		t.Errorf("expected id in state, got %s", id)
+	}
+}
+
+func TestDeleteWaitsForDeployment(t *testing.T) {
+	api := newFakeAPI(t)
+	api.taskStatuses = []string{"in_progress", "done"}
+	id := api.put(map[string]any{"name": "testname", "origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{}}}})
+	r := api.resource()
+
+	http_resource, err := r.proxy.GetHttpResource(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := resource.DeleteResponse{State: testState(t, testModel(t, http_resource))}
+	r.Delete(context.Background(), resource.DeleteRequest{State: resp.State}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Delete: %v", resp.Diagnostics)
+	}
+
+	stored, _ := api.get(id)
+	if stored["active"] != false {
+		t.Errorf("expected resource to be deactivated, got %v", stored["active"])
+	}
+	if len(api.taskPolls) != 1 {
+		t.Errorf("expected one task to be polled, got %v", api.taskPolls)
+	}
+}
+
+func TestCreateTimeout(t *testing.T) {
+	api := newFakeAPI(t)
+	api.taskStatuses = []string{"in_progress"}
+	r := api.resource()
+
+	plan := testCreatePlan(t)
+	plan.Timeouts = timeouts.Value{Object: types.ObjectValueMust(TimeoutsModel{}.AttributeTypes(), map[string]attr.Value{
+		"create": types.StringValue("50ms"),
+		"read":   types.StringNull(),
+		"update": types.StringNull(),
+		"delete": types.StringNull(),
+	})}
+
+	start := time.Now()
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, plan), Plan: testPlan(t, plan)}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected Create to time out")
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, context.DeadlineExceeded.Error()) {
+		t.Errorf("expected deadline error, got %q", detail)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("create timeout was not enforced, took %s", elapsed)
+	}
+}
+
+func TestImportState(t *testing.T) {
+	api := newFakeAPI(t)
+	id := api.put(map[string]any{
+		"name":   "dashboard",
+		"origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{"port": 443}}},
+		"names":  []string{"cdn.example.com"},
+		"cache":  map[string]any{"consider_args": true},
+	})
+	r := api.resource()
+
+	resp := resource.ImportStateResponse{State: emptyState(t)}
+	r.ImportState(context.Background(), resource.ImportStateRequest{ID: id}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("ImportState: %v", resp.Diagnostics)
+	}
+
+	http_resource, err := r.proxy.GetHttpResource(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := testModel(t, http_resource)
+	if !resp.State.Raw.Equal(testState(t, expected).Raw) {
+		t.Errorf("unexpected imported state:\n%s\nexpected:\n%+v", resp.State.Raw, expected)
+	}
+}
+
+// TestImportStateVerify checks that importing a resource created by
+// Terraform reproduces the state written by Create.
+func TestImportStateVerify(t *testing.T) {
+	api := newFakeAPI(t)
+	r := api.resource()
+
+	create_resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, testCreatePlan(t)), Plan: testPlan(t, testCreatePlan(t))}, &create_resp)
+	if create_resp.Diagnostics.HasError() {
+		t.Fatalf("Create: %v", create_resp.Diagnostics)
+	}
+	created := stateModel(t, create_resp.State)
+
+	import_resp := resource.ImportStateResponse{State: emptyState(t)}
+	r.ImportState(context.Background(), resource.ImportStateRequest{ID: created.ID.ValueString()}, &import_resp)
+	if import_resp.Diagnostics.HasError() {
+		t.Fatalf("ImportState: %v", import_resp.Diagnostics)
+	}
+
+	if !import_resp.State.Raw.Equal(create_resp.State.Raw) {
+		t.Errorf("imported state differs from created state:\n%s\n%s", import_resp.State.Raw, create_resp.State.Raw)
+	}
+}
+
+func TestImportStateNotFound(t *testing.T) {
+	api := newFakeAPI(t)
+	r := api.resource()
+
+	resp := resource.ImportStateResponse{State: emptyState(t)}
+	r.ImportState(context.Background(), resource.ImportStateRequest{ID: "42"}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected ImportState to fail")
+	}
+	if summary := resp.Diagnostics.Errors()[0].Summary(); summary != "Unable to import cdn http resource" {
+		t.Errorf("unexpected diagnostic: %s", summary)
+	}
+}
+
+func TestImportStateByNameOrDomain(t *testing.T) {
+	api := newFakeAPI(t)
+	origin := map[string]any{"servers": map[string]any{"example.com": map[string]any{}}}
+	api.put(map[string]any{"name": "first", "origin": origin})
+	id := api.put(map[string]any{"name": "second", "origin": origin})
+	api.put(map[string]any{"name": "duplicate", "origin": origin})
+	api.put(map[string]any{"name": "duplicate", "origin": origin})
+	r := api.resource()
+
+	for _, import_id := range []string{"name:second", "domain:cdn" + id + ".example.net"} {
+		resp := resource.ImportStateResponse{State: emptyState(t)}
+		r.ImportState(context.Background(), resource.ImportStateRequest{ID: import_id}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("ImportState(%s): %v", import_id, resp.Diagnostics)
+		}
+		if state := stateModel(t, resp.State); state.ID.ValueString() != id || state.Name.ValueString() != "second" {
+			t.Errorf("ImportState(%s): unexpected state %+v", import_id, state)
+		}
+	}
+
+	cases := map[string]string{
+		"name:missing":          "Cdn http resource not found",
+		"domain:cdn.example.io": "Cdn http resource not found",
+		"name:duplicate":        "Multiple cdn http resources found",
+		"name:":                 "Invalid import identifier",
+		"":                      "Invalid import identifier",
+	}
+	for import_id, expected := range cases {
+		resp := resource.ImportStateResponse{State: emptyState(t)}
+		r.ImportState(context.Background(), resource.ImportStateRequest{ID: import_id}, &resp)
+		if !resp.Diagnostics.HasError() {
+			t.Errorf("ImportState(%s): expected an error", import_id)
+			continue
+		}
+		if summary := resp.Diagnostics.Errors()[0].Summary(); summary != expected {
+			t.Errorf("ImportState(%s): expected %q, got %q", import_id, expected, summary)
+		}
+	}
+}
+
+func TestReadRemovesMissingResource(t *testing.T) {
+	api := newFakeAPI(t)
+	r := api.resource()
+
+	model := testModel(t, testApiResource())
+	model.ID = types.StringValue("42")
+
+	resp := resource.ReadResponse{State: testState(t, model)}
+	r.Read(context.Background(), resource.ReadRequest{State: resp.State}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Read: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Errorf("expected resource to be removed from state, got %s", resp.State.Raw)
+	}
+}
+
+func TestReadDeactivatedResource(t *testing.T) {
+	for _, remove_deactivated := range []bool{false, true} {
+		api := newFakeAPI(t)
+		id := api.put(map[string]any{"name": "testname", "origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{}}}})
+		r := api.resource()
+
+		http_resource, err := r.proxy.GetHttpResource(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		model := testModel(t, http_resource)
+		model.RemoveDeactivated = types.BoolValue(remove_deactivated)
+
+		api.mutex.Lock()
+		api.resources[id]["active"] = false
+		api.mutex.Unlock()
+
+		resp := resource.ReadResponse{State: testState(t, model)}
+		r.Read(context.Background(), resource.ReadRequest{State: resp.State}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("Read: %v", resp.Diagnostics)
+		}
+
+		if removed := resp.State.Raw.IsNull(); removed != remove_deactivated {
+			t.Errorf("remove_deactivated = %t: expected removed %t, got %t", remove_deactivated, remove_deactivated, removed)
+		}
+		if !remove_deactivated && stateModel(t, resp.State).Active.ValueBool() {
+			t.Error("expected active to be refreshed to false")
+		}
+	}
+}
+
+func TestDeleteModeDelete(t *testing.T) {
+	api := newFakeAPI(t)
+	id := api.put(map[string]any{"name": "testname", "origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{}}}})
+	r := api.resource()
+
+	http_resource, err := r.proxy.GetHttpResource(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := testModel(t, http_resource)
+	model.DeletionMode = types.StringValue(deletionModeDelete)
+
+	resp := resource.DeleteResponse{State: testState(t, model)}
+	r.Delete(context.Background(), resource.DeleteRequest{State: resp.State}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Delete: %v", resp.Diagnostics)
+	}
+
+	if _, ok := api.get(id); ok {
+		t.Error("expected resource to be deleted")
+	}
+	if last := api.requests[len(api.requests)-2]; last.Method != "DELETE" {
+		t.Errorf("expected DELETE request before the task poll, got %s %s", last.Method, last.Path)
+	}
+}
+
+func TestDeleteAlreadyRemoved(t *testing.T) {
+	for _, mode := range []string{deletionModeDeactivate, deletionModeDelete} {
+		api := newFakeAPI(t)
+		r := api.resource()
+
+		model := testModel(t, testApiResource())
+		model.ID = types.StringValue("42")
+		model.DeletionMode = types.StringValue(mode)
+
+		resp := resource.DeleteResponse{State: testState(t, model)}
+		r.Delete(context.Background(), resource.DeleteRequest{State: resp.State}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Errorf("Delete with mode %s: %v", mode, resp.Diagnostics)
+		}
+	}
+}
+
+func TestDeleteProtected(t *testing.T) {
+	api := newFakeAPI(t)
+	id := api.put(map[string]any{"name": "testname", "origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{}}}})
+	r := api.resource()
+
+	http_resource, err := r.proxy.GetHttpResource(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := testModel(t, http_resource)
+	model.DeletionProtection = types.BoolValue(true)
+	requests := len(api.requests)
+
+	resp := resource.DeleteResponse{State: testState(t, model)}
+	r.Delete(context.Background(), resource.DeleteRequest{State: resp.State}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected Delete to fail")
+	}
+	if summary := resp.Diagnostics.Errors()[0].Summary(); summary != "Cdn http resource is protected from deletion" {
+		t.Errorf("unexpected diagnostic: %s", summary)
+	}
+
+	if len(api.requests) != requests {
+		t.Errorf("expected no api requests, got %v", api.requests[requests:])
+	}
+	if stored, _ := api.get(id); stored["active"] != true {
+		t.Errorf("expected resource to stay active, got %v", stored["active"])
+	}
+}
+
+func TestCreateAdoptsDeactivatedResource(t *testing.T) {
+	api := newFakeAPI(t)
+	origin := map[string]any{"servers": map[string]any{"old.example.com": map[string]any{}}}
+	api.put(map[string]any{"name": "testname", "origin": origin})
+	id := api.put(map[string]any{"name": "testname", "origin": origin, "active": false})
+	r := api.resource()
+
+	plan := testCreatePlan(t)
+	plan.AdoptExisting = types.BoolValue(true)
+
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, plan), Plan: testPlan(t, plan)}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create: %v", resp.Diagnostics)
+	}
+
+	if state := stateModel(t, resp.State); state.ID.ValueString() != id || !state.Active.ValueBool() {
+		t.Errorf("expected adopted resource %s to be active, got %+v", id, state)
+	}
+	stored, _ := api.get(id)
+	if stored["active"] != true {
+		t.Errorf("expected adopted resource to be reactivated, got %v", stored["active"])
+	}
+	if servers := stored["origin"].(map[string]any)["servers"].(map[string]any); servers["example.com"] == nil {
+		t.Errorf("expected adopted resource configuration to be replaced, got %v", servers)
+	}
+	if len(api.resources) != 2 {
+		t.Errorf("expected no new resource, got %d", len(api.resources))
+	}
+}
+
+func TestCreateAdoptExistingWithoutMatch(t *testing.T) {
+	api := newFakeAPI(t)
+	api.put(map[string]any{"name": "testname", "origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{}}}})
+	r := api.resource()
+
+	plan := testCreatePlan(t)
+	plan.AdoptExisting = types.BoolValue(true)
+
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, plan), Plan: testPlan(t, plan)}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create: %v", resp.Diagnostics)
+	}
+
+	// The active resource with the same name is left alone
+	if state := stateModel(t, resp.State); state.ID.ValueString() != "2" {
+		t.Errorf("expected a new resource, got %s", state.ID)
+	}
+}
+
+func TestCreateAdoptExistingAmbiguous(t *testing.T) {
+	api := newFakeAPI(t)
+	origin := map[string]any{"servers": map[string]any{"example.com": map[string]any{}}}
+	api.put(map[string]any{"name": "testname", "origin": origin, "active": false})
+	api.put(map[string]any{"name": "testname", "origin": origin, "active": false})
+	r := api.resource()
+
+	plan := testCreatePlan(t)
+	plan.AdoptExisting = types.BoolValue(true)
+
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, plan), Plan: testPlan(t, plan)}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected Create to fail")
+	}
+	if summary := resp.Diagnostics.Errors()[0].Summary(); summary != "Multiple cdn http resources to adopt" {
+		t.Errorf("unexpected diagnostic: %s", summary)
+	}
+}
+
+func TestCreateStateFromApi(t *testing.T) {
+	api := newFakeAPI(t)
+	api.normalize = func(resource map[string]any) {
+		resource["name"] = strings.TrimSpace(resource["name"].(string))
+		resource["cache"] = map[string]any{}
+		if names, ok := resource["names"].([]any); ok && len(names) == 2 {
+			resource["names"] = []any{names[1], names[0]}
+		}
+		resource["ioss"] = true
+	}
+	r := api.resource()
+
+	plan := testCreatePlan(t)
+	plan.Name = types.StringValue("testname ")
+	plan.Names = types.SetValueMust(types.StringType, []attr.Value{types.StringValue("a.example.com"), types.StringValue("b.example.com")})
+	plan.IOSS = types.BoolUnknown()
+
+	resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, plan), Plan: testPlan(t, plan)}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Create: %v", resp.Diagnostics)
+	}
+
+	state := stateModel(t, resp.State)
+	if state.Name.ValueString() != "testname " {
+		t.Errorf("expected planned name to be kept, got %q", state.Name.ValueString())
+	}
+	if !state.Cache.IsNull() {
+		t.Errorf("expected empty cache from the api to stay null, got %s", state.Cache)
+	}
+	if !state.Names.Equal(plan.Names) {
+		t.Errorf("expected planned names, got %s", state.Names)
+	}
+	if !state.IOSS.ValueBool() {
+		t.Errorf("expected unknown ioss to be read from the api, got %s", state.IOSS)
+	}
+	if state.ID.ValueString() != "1" || state.CdnDomain.ValueString() != "cdn1.example.net" || state.CreationTs.ValueInt64() == 0 {
+		t.Errorf("expected computed attributes from the api, got %+v", state)
+	}
+}
+
+func TestUpdateSendsMergePatch(t *testing.T) {
+	base := `{
+		"name": "testname",
+		"origin": {"servers": {"example.com": {"port": 443}}},
+		"cache": {"valid": {"2xx": "1d", "4xx": "1s"}},
+		"headers": {"request": {"x-old": "1", "x-new": "2"}}
+	}`
+
+	cases := map[string]struct {
+		planned  string
+		expected string
+	}{
+		"top-level field": {
+			planned: `{
+				"name": "renamed",
+				"origin": {"servers": {"example.com": {"port": 443}}},
+				"cache": {"valid": {"2xx": "1d", "4xx": "1s"}},
+				"headers": {"request": {"x-old": "1", "x-new": "2"}}
+			}`,
+			expected: `{"name":"renamed"}`,
+		},
+		"nested field": {
+			planned: `{
+				"name": "testname",
+				"origin": {"servers": {"example.com": {"port": 8443}}},
+				"cache": {"valid": {"2xx": "2d", "4xx": "1s"}},
+				"headers": {"request": {"x-old": "1", "x-new": "2"}}
+			}`,
+			expected: `{"cache":{"valid":{"2xx":"2d"}},"origin":{"servers":{"example.com":{"port":8443}}}}`,
+		},
+		"removed fields": {
+			planned: `{
+				"name": "testname",
+				"origin": {"servers": {"example.com": {"port": 443}}},
+				"headers": {"request": {"x-new": "2"}}
+			}`,
+			expected: `{"cache":null,"headers":{"request":{"x-old":null}}}`,
+		},
+		"new block": {
+			planned: `{
+				"name": "testname",
+				"origin": {"servers": {"example.com": {"port": 443}}},
+				"cache": {"valid": {"2xx": "1d", "4xx": "1s"}},
+				"headers": {"request": {"x-old": "1", "x-new": "2"}},
+				"compress": {"gzip": true}
+			}`,
+			expected: `{"compress":{"gzip":true}}`,
+		},
+	}
+
+	for name, c := range cases {
+		api := newFakeAPI(t)
+		stored := map[string]any{}
+		if err := json.Unmarshal([]byte(base), &stored); err != nil {
+			t.Fatal(err)
+		}
+		stored["unmodeled"] = "keep"
+		id := api.put(stored)
+		r := api.resource()
+
+		prior, err := r.proxy.GetHttpResource(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var planned configuration.CdnHttpResource
+		if err := json.Unmarshal([]byte(c.planned), &planned); err != nil {
+			t.Fatal(err)
+		}
+		planned.ID, planned.CdnDomain, planned.CreationTs, planned.Active = prior.ID, prior.CdnDomain, prior.CreationTs, prior.Active
+
+		requests := len(api.requests)
+		resp := resource.UpdateResponse{State: testState(t, testModel(t, prior))}
+		r.Update(context.Background(), resource.UpdateRequest{
+			Config: testConfig(t, testModel(t, planned)),
+			Plan:   testPlan(t, testModel(t, planned)),
+			State:  resp.State,
+		}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: Update: %v", name, resp.Diagnostics)
+		}
+
+		var patches []string
+		for _, request := range api.requests[requests:] {
+			if request.Method == "PATCH" {
+				patches = append(patches, request.Body)
+			}
+		}
+		if len(patches) != 1 || strings.TrimSpace(patches[0]) != c.expected {
+			t.Errorf("%s: expected patch %s, got %v", name, c.expected, patches)
+		}
+
+		if stored, _ := api.get(id); stored["unmodeled"] != "keep" {
+			t.Errorf("%s: unmodeled field was not kept: %v", name, stored)
+		}
+	}
+}
+
+func TestUpdateWithoutChanges(t *testing.T) {
+	api := newFakeAPI(t)
+	id := api.put(map[string]any{"name": "testname", "origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{}}}})
+	r := api.resource()
+
+	http_resource, err := r.proxy.GetHttpResource(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := testModel(t, http_resource)
+	model.WaitForDeployment = types.BoolValue(false)
+
+	requests := len(api.requests)
+	resp := resource.UpdateResponse{State: testState(t, testModel(t, http_resource))}
+	r.Update(context.Background(), resource.UpdateRequest{Config: testConfig(t, model), Plan: testPlan(t, model), State: resp.State}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Update: %v", resp.Diagnostics)
+	}
+
+	for _, request := range api.requests[requests:] {
+		if request.Method != "GET" {
+			t.Errorf("expected only reads for a provider setting change, got %s %s", request.Method, request.Path)
+		}
+	}
+	if state := stateModel(t, resp.State); state.WaitForDeployment.ValueBool() {
+		t.Error("expected wait_for_deployment to be updated in state")
+	}
+}
+
+func TestUpdateDetectsRemoteChanges(t *testing.T) {
+	api := newFakeAPI(t)
+	id := api.put(map[string]any{
+		"name":   "testname",
+		"origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{"port": 443}}},
+		"cache":  map[string]any{"valid": map[string]any{"2xx": "1d"}},
+	})
+	r := api.resource()
+
+	prior, err := r.proxy.GetHttpResource(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planned := testModel(t, prior)
+	planned.Name = types.StringValue("renamed")
+
+	api.edit(id, func(stored map[string]any) {
+		stored["cache"] = map[string]any{"valid": map[string]any{"2xx": "2d"}}
+		stored["tuning"] = "large"
+	})
+
+	requests := len(api.requests)
+	resp := resource.UpdateResponse{State: testState(t, testModel(t, prior))}
+	r.Update(context.Background(), resource.UpdateRequest{Config: testConfig(t, planned), Plan: testPlan(t, planned), State: resp.State}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected a conflict error")
+	}
+	detail := resp.Diagnostics.Errors()[0].Detail()
+	for _, line := range []string{`~ cache.valid.2xx = "1d" -> "2d"`, `+ tuning = "large"`, "force_overwrite"} {
+		if !strings.Contains(detail, line) {
+			t.Errorf("expected diagnostic to contain %q, got %s", line, detail)
+		}
+	}
+	for _, request := range api.requests[requests:] {
+		if request.Method != "GET" {
+			t.Errorf("expected no changes to be sent, got %s %s", request.Method, request.Path)
+		}
+	}
+
+	planned.ForceOverwrite = types.BoolValue(true)
+	resp = resource.UpdateResponse{State: testState(t, testModel(t, prior))}
+	r.Update(context.Background(), resource.UpdateRequest{Config: testConfig(t, planned), Plan: testPlan(t, planned), State: resp.State}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Update: %v", resp.Diagnostics)
+	}
+	stored, _ := api.get(id)
+	if stored["name"] != "renamed" || stored["tuning"] != nil {
+		t.Errorf("expected remote changes to be overwritten, got %v", stored)
+	}
+	if valid := stored["cache"].(map[string]any)["valid"].(map[string]any); valid["2xx"] != "1d" {
+		t.Errorf("expected cache.valid.2xx to be overwritten, got %v", valid)
+	}
+}
+
+func TestUpdateIgnoresNormalizedRemoteValues(t *testing.T) {
+	api := newFakeAPI(t)
+	id := api.put(map[string]any{
+		"name":   "testname",
+		"origin": map[string]any{"servers": map[string]any{"example.com": map[string]any{}}},
+		"cache":  map[string]any{"valid": map[string]any{"2xx": "60s"}},
+	})
+	r := api.resource()
+
+	prior, err := r.proxy.GetHttpResource(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planned := testModel(t, prior)
+	planned.Name = types.StringValue("renamed")
+
+	api.edit(id, func(stored map[string]any) {
+		stored["cache"] = map[string]any{"valid": map[string]any{"2xx": "1m"}}
+		stored["unmodeled"] = "changed"
+	})
+
+	resp := resource.UpdateResponse{State: testState(t, testModel(t, prior))}
+	r.Update(context.Background(), resource.UpdateRequest{Config: testConfig(t, planned), Plan: testPlan(t, planned), State: resp.State}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Update: %v", resp.Diagnostics)
+	}
+}
+
+func TestRemoteChangesDurations(t *testing.T) {
+	resource := func(timeout, valid, header, location_valid string) configuration.CdnHttpResource {
+		var http_resource configuration.CdnHttpResource
+		body := fmt.Sprintf(`{
+			"origin": {"servers": {"example.com": {}}, "read_timeout": %q},
+			"cache": {"valid": {"2xx": %q}},
+			"headers": {"request": {"x-ttl": %q}},
+			"locations": {"/v1.2": {"cache": {"valid": {"2xx": %q}}}}
+		}`, timeout, valid, header, location_valid)
+		if err := json.Unmarshal([]byte(body), &http_resource); err != nil {
+			t.Fatal(err)
+		}
+		return http_resource
+	}
+
+	changes, err := remoteChanges(resource("60s", "1d", "60", "1h"), resource("1m", "24h", "1m", "60m"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "headers.request.x-ttl" {
+		t.Errorf("expected only the header to be changed, got %+v", changes)
+	}
+}
+
+func TestWriteOnlySecrets(t *testing.T) {
+	api := newFakeAPI(t)
+	r := api.resource()
+
+	access_key, secret_key, md5_secret := "access", "aws-secret", "md5-secret"
+	version := int64(1)
+	prefix_match := configuration.LocationMatchPrefix
+	configured := testApiResource()
+	configured.Origin.AWS = &configuration.AWS{Auth: &configuration.AWSAuth{
+		AccessKey:          &access_key,
+		SecretKeyWO:        &secret_key,
+		SecretKeyWOVersion: &version,
+	}}
+	configured.Locations = configuration.LocationList{{Path: "/private", Locations: configuration.Locations{
+		Auth: &configuration.Auth{Md5: &configuration.Md5Auth{SecretWO: &md5_secret, SecretWOVersion: &version}},
+	}}}
+
+	// Terraform passes write-only values in the configuration only
+	config := testCreatePlan(t)
+	config.Origin = testModel(t, configured).Origin
+	config.Location = testModel(t, configured).Location
+	planned := configured
+	planned.Origin = &configuration.Origin{Servers: configured.Origin.Servers, AWS: &configuration.AWS{Auth: &configuration.AWSAuth{
+		AccessKey:          &access_key,
+		SecretKeyWOVersion: &version,
+	}}}
+	planned.Locations = configuration.LocationList{{Path: "/private", Match: &prefix_match, Locations: configuration.Locations{
+		Auth: &configuration.Auth{Md5: &configuration.Md5Auth{SecretWOVersion: &version}},
+	}}}
+	plan := testCreatePlan(t)
+	plan.Origin = testModel(t, planned).Origin
+	plan.Location = testModel(t, planned).Location
+
+	create_resp := resource.CreateResponse{State: emptyState(t)}
+	r.Create(context.Background(), resource.CreateRequest{Config: testConfig(t, config), Plan: testPlan(t, plan)}, &create_resp)
+	if create_resp.Diagnostics.HasError() {
+		t.Fatalf("Create: %v", create_resp.Diagnostics)
+	}
+
+	stored, _ := api.get("1")
+	body, _ := json.Marshal(stored)
+	for _, secret := range []string{`"secret_key":"aws-secret"`, `"secret":"md5-secret"`} {
+		if !strings.Contains(string(body), secret) {
+			t.Errorf("expected %s to be sent, got %s", secret, body)
+		}
+	}
+
+	read_resp := resource.ReadResponse{State: create_resp.State}
+	r.Read(context.Background(), resource.ReadRequest{State: create_resp.State}, &read_resp)
+	if read_resp.Diagnostics.HasError() {
+		t.Fatalf("Read: %v", read_resp.Diagnostics)
+	}
+
+	for name, state := range map[string]tfsdk.State{"create": create_resp.State, "read": read_resp.State} {
+		for _, secret_path := range []path.Path{
+			path.Root("origin").AtName("aws").AtName("auth").AtName("secret_key"),
+			path.Root("origin").AtName("aws").AtName("auth").AtName("secret_key_wo"),
+			path.Root("location").AtListIndex(0).AtName("auth").AtName("md5").AtName("secret"),
+		} {
+			var value types.String
+			state.GetAttribute(context.Background(), secret_path, &value)
+			if !value.IsNull() {
+				t.Errorf("%s: expected %s to be null in state, got %s", name, secret_path, value)
+			}
+		}
+
+		var stored_version types.Int64
+		state.GetAttribute(context.Background(), path.Root("origin").AtName("aws").AtName("auth").AtName("secret_key_wo_version"), &stored_version)
+		if stored_version.ValueInt64() != version {
+			t.Errorf("%s: expected secret_key_wo_version to be kept, got %s", name, stored_version)
+		}
+		var plain_key types.String
+		state.GetAttribute(context.Background(), path.Root("origin").AtName("aws").AtName("auth").AtName("access_key"), &plain_key)
+		if plain_key.ValueString() != access_key {
+			t.Errorf("%s: expected access_key in state, got %s", name, plain_key)
+		}
+	}
+
+	// A new version sends the new secret
+	secret_key, version = "rotated", 2
+	config.Origin = testModel(t, configured).Origin
+	config.ID, config.CdnDomain, config.CreationTs = types.StringValue("1"), stateModel(t, read_resp.State).CdnDomain, stateModel(t, read_resp.State).CreationTs
+	plan = stateModel(t, read_resp.State)
+	plan.Origin = testModel(t, planned).Origin
+
+	update_resp := resource.UpdateResponse{State: read_resp.State}
+	r.Update(context.Background(), resource.UpdateRequest{Config: testConfig(t, config), Plan: testPlan(t, plan), State: read_resp.State}, &update_resp)
+	if update_resp.Diagnostics.HasError() {
+		t.Fatalf("Update: %v", update_resp.Diagnostics)
+	}
+	stored, _ = api.get("1")
+	body, _ = json.Marshal(stored)
+	if !strings.Contains(string(body), `"secret_key":"rotated"`) {
+		t.Errorf("expected the rotated secret to be sent, got %s", body)
+	}
+}
+
+func TestWriteOnlyVersionsFollowLocations(t *testing.T) {
+	api := newFakeAPI(t)
+	tf := newTestTerraform(t, api)
+
+	md5_secret, version := "md5-secret", int64(1)
+	private := configuration.Locations{Auth: &configuration.Auth{Md5: &configuration.Md5Auth{SecretWO: &md5_secret, SecretWOVersion: &version}}}
+	status := 403
+
+	http_resource := testApiResource()
+	http_resource.Locations = configuration.LocationList{{Path: "/private", Locations: private}}
+	config := testResourceConfig(t, http_resource)
+	null_state := tftypes.NewValue(config.Type(), nil)
+	state := tf.refresh(tf.apply(null_state, tf.plan(null_state, config), config))
+	tf.expectNoChanges("create", state, config)
+
+	// The version stays with its location when another one is inserted in
+	// front, outside of Terraform or by the configuration
+	api.edit("1", func(stored map[string]any) {
+		locations := stored["locations"].(*fakeLocations)
+		locations.keys = append([]string{"/public"}, locations.keys...)
+		locations.values["/public"] = map[string]any{"return_http_status_code": status}
+	})
+	state = tf.refresh(state)
+	expectVersions := func(step string, state tftypes.Value, expected ...types.Int64) {
+		t.Helper()
+		state_resource := tfsdk.State{Schema: tf.schema, Raw: state}
+		for i, expected := range expected {
+			var stored_version types.Int64
+			state_resource.GetAttribute(context.Background(), path.Root("location").AtListIndex(i).AtName("auth").AtName("md5").AtName("secret_wo_version"), &stored_version)
+			if !stored_version.Equal(expected) {
+				t.Errorf("%s: expected secret_wo_version of location %d to be %s, got %s", step, i, expected, stored_version)
+			}
+		}
+	}
+	expectVersions("remote insert", state, types.Int64Null(), types.Int64Value(version))
+
+	public := configuration.Locations{ReturnHTTPStatusCode: &status}
+	http_resource.Locations = configuration.LocationList{{Path: "/public", Locations: public}, {Path: "/private", Locations: private}}
+	config = testResourceConfig(t, http_resource)
+	tf.expectNoChanges("remote insert", state, config)
+
+	http_resource.Locations = append(configuration.LocationList{{Path: "/", Locations: public}}, http_resource.Locations...)
+	config = testResourceConfig(t, http_resource)
+	state = tf.refresh(tf.apply(state, tf.plan(state, config), config))
+	expectVersions("insert", state, types.Int64Null(), types.Int64Null(), types.Int64Value(version))
+	tf.expectNoChanges("insert", state, config)
+}
+
+// storedLocationKeys returns the location keys of a resource in the order the
+// api keeps them.
+func storedLocationKeys(t *testing.T, api *fakeAPI, id string) []string {
+	stored, _ := api.get(id)
+	body, _ := json.Marshal(stored)
+	var ordered struct {
+		Locations configuration.LocationList `json:"locations"`
+	}
+	if err := json.Unmarshal(body, &ordered); err != nil {
+		t.Fatal(err)
+	}
+	return ordered.Locations.Keys()
+}
+
+func TestLocationOrder(t *testing.T) {
+	api := newFakeAPI(t)
+	tf := newTestTerraform(t, api)
+
+	exact, regex := configuration.LocationMatchExact, configuration.LocationMatchRegexCaseInsensitive
+	status := func(code int) configuration.Locations { return configuration.Locations{ReturnHTTPStatusCode: &code} }
+
+	http_resource := testApiResource()
+	http_resource.Locations = configuration.LocationList{
+		{Path: "/video", Locations: status(403)},
+		{Path: `\.mp4$`, Match: &regex, Locations: status(404)},
+		{Path: "/", Match: &exact, Locations: status(204)},
+	}
+	config := testResourceConfig(t, http_resource)
+	null_state := tftypes.NewValue(config.Type(), nil)
+
+	state := tf.refresh(tf.apply(null_state, tf.plan(null_state, config), config))
+	if keys, expected := storedLocationKeys(t, api, "1"), []string{"/video", `~* \.mp4$`, "= /"}; !slices.Equal(keys, expected) {
+		t.Errorf("expected locations %q to be created, got %q", expected, keys)
+	}
+	tf.expectNoChanges("create", state, config)
+
+	// Moving a location sends all of them in the new order
+	http_resource.Locations = configuration.LocationList{
+		{Path: "/", Match: &exact, Locations: status(204)},
+		{Path: `\.mp4$`, Match: &regex},
+		{Path: "/images", Locations: status(403)},
+	}
+	config = testResourceConfig(t, http_resource)
+	requests := len(api.requests)
+	state = tf.refresh(tf.apply(state, tf.plan(state, config), config))
+
+	expected := []string{"= /", `~* \.mp4$`, "/images"}
+	if keys := storedLocationKeys(t, api, "1"); !slices.Equal(keys, expected) {
+		t.Errorf("expected locations %q after the update, got %q", expected, keys)
+	}
+	patched := false
+	for _, request := range api.requests[requests:] {
+		if request.Method != http.MethodPatch {
+			continue
+		}
+		patched = true
+		if !strings.Contains(request.Body, `"~* \\.mp4$":{"return_http_status_code":null}`) || !strings.Contains(request.Body, `"/video":null`) {
+			t.Errorf("expected removed locations and fields to be set to null, got %s", request.Body)
+		}
+	}
+	if !patched {
+		t.Errorf("expected the update to be sent as a patch, got %v", api.requests[requests:])
+	}
+	tf.expectNoChanges("update", state, config)
+
+	// Inserting a location in front does not give it the settings of the
+	// location that was there before, nor its server defaults
+	api.normalize = injectServerDefaults
+	var cached configuration.Locations
+	if err := json.Unmarshal([]byte(`{"cache": {"consider_args": true, "args_whitelist": ["p"], "valid": {"2xx": "7d"}}}`), &cached); err != nil {
+		t.Fatal(err)
+	}
+	http_resource.Locations = configuration.LocationList{{Path: "/a", Locations: cached}}
+	config = testResourceConfig(t, http_resource)
+	state = tf.refresh(tf.apply(state, tf.plan(state, config), config))
+	tf.expectNoChanges("single location", state, config)
+
+	http_resource.Locations = configuration.LocationList{
+		{Path: "/new", Locations: status(403)},
+		{Path: "/a", Locations: cached},
+	}
+	config = testResourceConfig(t, http_resource)
+	state = tf.refresh(tf.apply(state, tf.plan(state, config), config))
+
+	stored, _ := api.get("1")
+	locations, _ := json.Marshal(stored["locations"])
+	var inserted map[string]struct {
+		Cache struct {
+			ConsiderArgs  bool     `json:"consider_args"`
+			ArgsWhitelist []string `json:"args_whitelist"`
+			Valid         map[string]any
+		} `json:"cache"`
+	}
+	if err := json.Unmarshal(locations, &inserted); err != nil {
+		t.Fatal(err)
+	}
+	if cache := inserted["/new"].Cache; cache.ConsiderArgs || cache.ArgsWhitelist != nil || cache.Valid["2xx"] != nil {
+		t.Errorf("expected the inserted location to keep the default cache settings, got %+v", cache)
+	}
+	if cache := inserted["/a"].Cache; !cache.ConsiderArgs || !slices.Equal(cache.ArgsWhitelist, []string{"p"}) || cache.Valid["2xx"] != "7d" {
+		t.Errorf("expected the moved location to keep its cache settings, got %+v", cache)
+	}
+	tf.expectNoChanges("insert in front", state, config)
+}
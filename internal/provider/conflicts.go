@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+// readApiRequest reads the resource from the api and returns it in the form
+// of an api request generated from state, so that it can be compared with the
+// request generated from the prior state. Values that only differ from the
+// prior state by api normalization are taken from the prior state.
+func (resource *httpResource) readApiRequest(ctx context.Context, prior tfsdk.State, resource_id string) (configuration.CdnHttpResource, diag.Diagnostics) {
+	var all_diags diag.Diagnostics
+
+	http_resource, err := resource.proxy.GetHttpResource(ctx, resource_id)
+	if err != nil {
+		all_diags.Append(apiErrorDiagnostic(
+			ctx,
+			"Error getting cdn http resource",
+			"Could not get cdn http resource before updating it, unexpected error: ",
+			err,
+		))
+		return configuration.CdnHttpResource{}, all_diags
+	}
+
+	current_model, diags := GenerateState(http_resource, ctx)
+	all_diags.Append(diags...)
+	if all_diags.HasError() {
+		return configuration.CdnHttpResource{}, all_diags
+	}
+
+	current := tfsdk.State{Schema: prior.Schema}
+	all_diags.Append(current.Set(ctx, current_model)...)
+	if all_diags.HasError() {
+		return configuration.CdnHttpResource{}, all_diags
+	}
+	current.Raw = reconcileValue(prior.Raw, keepWriteOnlyVersions(prior.Raw, current.Raw))
+	all_diags.Append(current.Get(ctx, &current_model)...)
+	if all_diags.HasError() {
+		return configuration.CdnHttpResource{}, all_diags
+	}
+
+	request, diags := GenerateApiRequest(current_model, ctx)
+	all_diags.Append(diags...)
+
+	return request, all_diags
+}
+
+// durationFields are the api fields holding durations, relative to the
+// resource or to one of its locations.
+var durationFields = []string{
+	"origin.read_timeout",
+	"origin.send_timeout",
+	"origin.connect_timeout",
+	"cache.valid.2xx",
+	"cache.valid.3xx",
+	"cache.valid.4xx",
+	"cache.valid.5xx",
+}
+
+// isDurationField reports whether the dotted api field path is a duration.
+// Location keys may contain dots, so fields under locations are matched by
+// suffix.
+func isDurationField(field string) bool {
+	for _, duration_field := range durationFields {
+		if field == duration_field || (strings.HasPrefix(field, "locations.") && strings.HasSuffix(field, "."+duration_field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteChanges returns the fields changed outside of Terraform between the
+// prior state and the current api request. Durations written differently
+// but meaning the same time, e.g. "60s" and "1m", are not changes.
+func remoteChanges(prior, current configuration.CdnHttpResource) ([]configuration.FieldChange, error) {
+	changes, err := configuration.DiffFields(prior, current)
+	if err != nil {
+		return nil, err
+	}
+
+	remote_changes := []configuration.FieldChange{}
+	for _, change := range changes {
+		prior_value, prior_ok := change.Prior.(string)
+		current_value, current_ok := change.Current.(string)
+		if prior_ok && current_ok && isDurationField(change.Path) {
+			prior_duration, prior_err := parseDuration(prior_value)
+			current_duration, current_err := parseDuration(current_value)
+			if prior_err == nil && current_err == nil && prior_duration == current_duration {
+				continue
+			}
+		}
+		remote_changes = append(remote_changes, change)
+	}
+
+	return remote_changes, nil
+}
+
+// conflictDiagnostic describes changes made outside of Terraform as a diff:
+// "+" for added fields, "-" for removed ones and "~" for changed ones.
+func conflictDiagnostic(resource_id string, changes []configuration.FieldChange) diag.Diagnostic {
+	var lines []string
+	for _, change := range changes {
+		switch {
+		case change.Prior == nil:
+			lines = append(lines, fmt.Sprintf("  + %s = %s", change.Path, formatFieldValue(change.Current)))
+		case change.Current == nil:
+			lines = append(lines, fmt.Sprintf("  - %s = %s", change.Path, formatFieldValue(change.Prior)))
+		default:
+			lines = append(lines, fmt.Sprintf("  ~ %s = %s -> %s", change.Path, formatFieldValue(change.Prior), formatFieldValue(change.Current)))
+		}
+	}
+
+	return diag.NewErrorDiagnostic(
+		"Cdn http resource was changed outside of Terraform",
+		fmt.Sprintf(
+			"Cdn http resource %s was changed since it was last read, applying the plan would overwrite these changes:\n\n%s\n\n"+
+				"Run terraform apply again to plan against the current configuration, or set force_overwrite = true to overwrite the changes.",
+			resource_id,
+			strings.Join(lines, "\n"),
+		),
+	)
+}
+
+func formatFieldValue(value any) string {
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(formatted)
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+)
+
+// apiErrorDiagnostic renders a client error as a diagnostic. When the api
+// points at an attribute of the http resource the diagnostic is attached to
+// it, so Terraform highlights the offending configuration.
+func apiErrorDiagnostic(ctx context.Context, summary, detail string, err error) diag.Diagnostic {
+	detail = detail + err.Error()
+
+	var api_error *configuration.APIError
+	if !errors.As(err, &api_error) {
+		return diag.NewErrorDiagnostic(summary, detail)
+	}
+
+	switch {
+	case configuration.IsConflict(err):
+		detail += "\n\nThe resource conflicts with an existing one, for example a resource with the same name or CNAME."
+	case configuration.IsRateLimited(err):
+		detail += "\n\nThe API rate limit was exceeded and retries were exhausted. Consider lowering -parallelism or increasing retry settings."
+	case configuration.IsUnauthorized(err):
+		detail += "\n\nThe API rejected the provider credentials. Check the account_name and api_token or username and password settings."
+	}
+
+	if api_error.Field != "" {
+		if attribute_path, ok := httpResourceAttributePath(ctx, api_error.Field); ok {
+			return diag.NewAttributeErrorDiagnostic(attribute_path, summary, detail)
+		}
+	}
+
+	return diag.NewErrorDiagnostic(summary, detail)
+}
+
+// httpResourceAttributePath converts a dotted api field such as
+// "origin.servers.example.com.port" into a path in the http resource schema.
+// Segments are matched against the schema so map keys containing dots and
+// list indexes are resolved correctly.
+func httpResourceAttributePath(ctx context.Context, field string) (path.Path, bool) {
+	schema_response := resource.SchemaResponse{}
+	(&httpResource{}).Schema(ctx, resource.SchemaRequest{}, &schema_response)
+	resource_schema := schema_response.Schema
+
+	segments := strings.Split(field, ".")
+	// The api keys locations by path, which does not tell the index in the
+	// location list, so point at the whole list
+	if segments[0] == "locations" {
+		return path.Root("location"), true
+	}
+
+	attribute_path := path.Root(segments[0])
+	if !isSchemaPath(ctx, resource_schema, attribute_path) {
+		return path.Empty(), false
+	}
+
+	for i := 1; i < len(segments); i++ {
+		if candidate := attribute_path.AtName(segments[i]); isSchemaPath(ctx, resource_schema, candidate) {
+			attribute_path = candidate
+			continue
+		}
+
+		if index, err := strconv.Atoi(segments[i]); err == nil {
+			if candidate := attribute_path.AtListIndex(index); isSchemaPath(ctx, resource_schema, candidate) {
+				attribute_path = candidate
+				continue
+			}
+		}
+
+		// Map keys may contain dots, so take the shortest key after which
+		// the rest of the field still resolves
+		matched := false
+		for j := i + 1; j <= len(segments); j++ {
+			candidate := attribute_path.AtMapKey(strings.Join(segments[i:j], "."))
+			if !isSchemaPath(ctx, resource_schema, candidate) {
+				break
+			}
+			if j == len(segments) || isSchemaPath(ctx, resource_schema, candidate.AtName(segments[j])) {
+				attribute_path = candidate
+				i = j - 1
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			// Point at the closest attribute we could resolve
+			return attribute_path, true
+		}
+	}
+
+	return attribute_path, true
+}
+
+func isSchemaPath(ctx context.Context, resource_schema schema.Schema, attribute_path path.Path) bool {
+	_, diags := resource_schema.TypeAtPath(ctx, attribute_path)
+	return !diags.HasError()
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestHttpResourceAttributePath(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		field    string
+		expected path.Path
+		ok       bool
+	}{
+		{"name", path.Root("name"), true},
+		{"origin.hostname", path.Root("origin").AtName("hostname"), true},
+		{"origin.servers.example.com.port", path.Root("origin").AtName("servers").AtMapKey("example.com").AtName("port"), true},
+		{"locations./images.cache.valid.c_2xx", path.Root("location"), true},
+		{"cache.unknown_field", path.Root("cache"), true},
+		{"unknown_field", path.Empty(), false},
+	}
+
+	for _, c := range cases {
+		attribute_path, ok := httpResourceAttributePath(ctx, c.field)
+		if ok != c.ok || !attribute_path.Equal(c.expected) {
+			t.Errorf("%s: expected (%s, %t), got (%s, %t)", c.field, c.expected, c.ok, attribute_path, ok)
+		}
+	}
+}
+
+func TestApiErrorDiagnostic(t *testing.T) {
+	ctx := context.Background()
+
+	err := &configuration.APIError{HTTPStatus: http.StatusOK, Status: "reject", Message: "invalid", Field: "origin.hostname"}
+	diagnostic := apiErrorDiagnostic(ctx, "summary", "detail: ", err)
+	with_path, ok := diagnostic.(diag.DiagnosticWithPath)
+	if !ok {
+		t.Fatalf("expected attribute diagnostic, got %T", diagnostic)
+	}
+	if !with_path.Path().Equal(path.Root("origin").AtName("hostname")) {
+		t.Errorf("unexpected path: %s", with_path.Path())
+	}
+	if diagnostic.Detail() != "detail: message: invalid, field: origin.hostname" {
+		t.Errorf("unexpected detail: %s", diagnostic.Detail())
+	}
+
+	diagnostic = apiErrorDiagnostic(ctx, "summary", "detail: ", errors.New("connection refused"))
+	if _, ok := diagnostic.(diag.DiagnosticWithPath); ok {
+		t.Errorf("expected plain diagnostic for transport error")
+	}
+}
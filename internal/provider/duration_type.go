@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = DurationType{}
+	_ basetypes.StringValuableWithSemanticEquals = DurationValue{}
+	_ validator.String                           = durationValidator{}
+)
+
+// durationUnits are the units of the CDN duration grammar, nginx style.
+// A number without a unit means seconds.
+var durationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"M":  30 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+var (
+	durationPattern     = regexp.MustCompile(`^\s*(\d+(ms|s|m|h|d|w|M|y)\s*)+$`)
+	durationPartPattern = regexp.MustCompile(`(\d+)(ms|s|m|h|d|w|M|y)`)
+	secondsPattern      = regexp.MustCompile(`^\s*\d+\s*$`)
+)
+
+// parseDuration parses a CDN duration such as "10s", "1d" or "1h 30m".
+func parseDuration(value string) (time.Duration, error) {
+	if secondsPattern.MatchString(value) {
+		seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return addDuration(value, 0, seconds, time.Second)
+	}
+
+	if !durationPattern.MatchString(value) {
+		return 0, fmt.Errorf("%q is not a duration, expected a number followed by one of ms, s, m, h, d, w, M, y, e.g. \"10s\" or \"1h 30m\"", value)
+	}
+
+	var duration time.Duration
+	for _, part := range durationPartPattern.FindAllStringSubmatch(value, -1) {
+		amount, err := strconv.ParseInt(part[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		duration, err = addDuration(value, duration, amount, durationUnits[part[2]])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return duration, nil
+}
+
+// addDuration returns duration plus amount units of value, or an error when
+// the result does not fit in a time.Duration.
+func addDuration(value string, duration time.Duration, amount int64, unit time.Duration) (time.Duration, error) {
+	if amount < 0 || amount > int64(math.MaxInt64/unit) || duration > math.MaxInt64-time.Duration(amount)*unit {
+		return 0, fmt.Errorf("%q is out of range, durations are limited to %s", value, time.Duration(math.MaxInt64).Truncate(time.Hour))
+	}
+	return duration + time.Duration(amount)*unit, nil
+}
+
+// DurationType is a string attribute holding a CDN duration. Values are
+// compared by the time they represent, so "1m" and "60s" are equal.
+// Configured values are checked against the duration grammar by
+// validDuration rather than by the type, so that an unexpected format
+// returned by the api never prevents reading a resource.
+type DurationType struct {
+	basetypes.StringType
+}
+
+func (t DurationType) Equal(o attr.Type) bool {
+	other, ok := o.(DurationType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t DurationType) String() string {
+	return "DurationType"
+}
+
+func (t DurationType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return DurationValue{StringValue: in}, nil
+}
+
+func (t DurationType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	value, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	string_value, ok := value.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", value)
+	}
+
+	duration_value, diags := t.ValueFromString(ctx, string_value)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to DurationValue: %v", diags)
+	}
+
+	return duration_value, nil
+}
+
+func (t DurationType) ValueType(_ context.Context) attr.Value {
+	return DurationValue{}
+}
+
+// DurationValue is a value of DurationType.
+type DurationValue struct {
+	basetypes.StringValue
+}
+
+func (v DurationValue) Equal(o attr.Value) bool {
+	other, ok := o.(DurationValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v DurationValue) Type(_ context.Context) attr.Type {
+	return DurationType{}
+}
+
+// StringSemanticEquals reports whether both values are the same amount of time.
+func (v DurationValue) StringSemanticEquals(_ context.Context, new_value_valuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	new_value, ok := new_value_valuable.(DurationValue)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T. Please report this to the provider developers.", v, new_value_valuable),
+		)
+		return false, diags
+	}
+
+	prior, err := parseDuration(v.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	current, err := parseDuration(new_value.ValueString())
+	if err != nil {
+		return false, diags
+	}
+
+	return prior == current, diags
+}
+
+// NewDurationValue returns a known duration.
+func NewDurationValue(value string) DurationValue {
+	return DurationValue{StringValue: basetypes.NewStringValue(value)}
+}
+
+// NewDurationPointerValue returns a duration that is null when value is nil.
+func NewDurationPointerValue(value *string) DurationValue {
+	return DurationValue{StringValue: basetypes.NewStringPointerValue(value)}
+}
+
+// durationValidator checks that a configured string follows the CDN duration grammar.
+type durationValidator struct{}
+
+// validDuration returns a validator for attributes of DurationType.
+func validDuration() validator.String {
+	return durationValidator{}
+}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return `value must be a duration such as "10s", "1d" or "1h 30m"`
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := parseDuration(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Duration", err.Error())
+	}
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+)
+
+func TestParseDuration(t *testing.T) {
+	valid := map[string]time.Duration{
+		"10s":    10 * time.Second,
+		"60":     time.Minute,
+		"1m":     time.Minute,
+		"1d":     24 * time.Hour,
+		"1h 30m": 90 * time.Minute,
+		"1h30m":  90 * time.Minute,
+		"500ms":  500 * time.Millisecond,
+		"1M":     30 * 24 * time.Hour,
+		"2w":     14 * 24 * time.Hour,
+		"1y":     365 * 24 * time.Hour,
+	}
+	for value, expected := range valid {
+		duration, err := parseDuration(value)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", value, err)
+		} else if duration != expected {
+			t.Errorf("%q: expected %s, got %s", value, expected, duration)
+		}
+	}
+
+	// Values overflowing a time.Duration are rejected rather than wrapped
+	for _, value := range []string{"", "s", "10 seconds", "1.5h", "-1s", "1D", "300y", "292y 1y", "9223372037s", "9223372037", "99999999999999999999s"} {
+		if _, err := parseDuration(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestDurationSemanticEquals(t *testing.T) {
+	cases := []struct {
+		prior, current string
+		equal          bool
+	}{
+		{"1m", "60s", true},
+		{"1d", "24h", true},
+		{"60", "1m", true},
+		{"1m", "61s", false},
+		{"invalid", "invalid ", false},
+	}
+
+	for _, c := range cases {
+		equal, diags := NewDurationValue(c.prior).StringSemanticEquals(context.Background(), NewDurationValue(c.current))
+		if diags.HasError() {
+			t.Fatalf("%s and %s: %v", c.prior, c.current, diags)
+		}
+		if equal != c.equal {
+			t.Errorf("%s and %s: expected equal %t, got %t", c.prior, c.current, c.equal, equal)
+		}
+	}
+}
+
+func TestValidateConfigDuration(t *testing.T) {
+	http_resource := testApiResource()
+	read_timeout := "10 seconds"
+	http_resource.Origin.ReadTimeout = &read_timeout
+
+	errors := validateConfig(t, testModel(t, http_resource))
+	if len(errors) != 1 || errors[0].Summary != "Invalid Duration" {
+		t.Fatalf("expected an invalid duration error, got %v", errors)
+	}
+	if path := errors[0].Attribute.String(); path != `AttributeName("origin").AttributeName("read_timeout")` {
+		t.Errorf("unexpected error path %s", path)
+	}
+
+	valid := "1d"
+	http_resource.Origin.ReadTimeout = &valid
+	http_resource.Cache = &configuration.Cache{}
+	if errors := validateConfig(t, testModel(t, http_resource)); len(errors) != 0 {
+		t.Errorf("unexpected errors: %v", errors)
+	}
+}
@@ -0,0 +1,421 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// fakeRequest is a request received by fakeAPI.
+type fakeRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// fakeAPI is an in-memory stand-in for the CDNVideo configuration api used
+// to exercise the resource CRUD methods without network access.
+type fakeAPI struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mutex     sync.Mutex
+	resources map[string]map[string]any
+	next_id   int
+	requests  []fakeRequest
+
+	// taskStatuses are reported by every task in order, the last one repeating.
+	taskStatuses []string
+	taskPolls    map[string]int
+
+	// failReads makes reading a single resource fail.
+	failReads bool
+
+	// normalize, when set, rewrites every created, replaced or patched
+	// resource the way the real api applies defaults and formatting.
+	normalize func(resource map[string]any)
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	api := &fakeAPI{
+		t:            t,
+		resources:    map[string]map[string]any{},
+		taskStatuses: []string{"done"},
+		taskPolls:    map[string]int{},
+	}
+	api.server = httptest.NewServer(http.HandlerFunc(api.handle))
+	t.Cleanup(api.server.Close)
+
+	return api
+}
+
+func (api *fakeAPI) proxy() *configuration.ConfigurationApiProxy {
+	proxy, err := configuration.NewProxy(context.Background(), configuration.ProxyConfig{
+		Username:    "user",
+		Password:    "pass",
+		AccountName: "account",
+		ApiURL:      api.server.URL + "/api",
+		OauthURL:    api.server.URL + "/oauth/token/",
+	})
+	if err != nil {
+		api.t.Fatalf("NewProxy: %s", err)
+	}
+
+	return proxy
+}
+
+// resource returns a configured httpResource talking to the fake api.
+func (api *fakeAPI) resource() *httpResource {
+	return &httpResource{
+		proxy:            api.proxy(),
+		taskPollInterval: time.Millisecond,
+	}
+}
+
+// put stores a resource as if it was created outside of Terraform.
+func (api *fakeAPI) put(resource map[string]any) string {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	return api.store(resource)
+}
+
+// store adds a resource filling in server-side attributes. The caller must hold the mutex.
+func (api *fakeAPI) store(resource map[string]any) string {
+	api.next_id++
+	id := fmt.Sprint(api.next_id)
+	stored := map[string]any{
+		"id":          id,
+		"cdn_domain":  "cdn" + id + ".example.net",
+		"creation_ts": 1700000000 + api.next_id,
+		"active":      true,
+	}
+	for key, value := range resource {
+		stored[key] = value
+	}
+	if api.normalize != nil {
+		api.normalize(stored)
+	}
+	api.resources[id] = stored
+
+	return id
+}
+
+// edit changes a stored resource as if it was edited outside of Terraform.
+func (api *fakeAPI) edit(id string, change func(resource map[string]any)) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	change(api.resources[id])
+}
+
+// get returns a copy of the stored resource.
+func (api *fakeAPI) get(id string) (map[string]any, bool) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	stored, ok := api.resources[id]
+	if !ok {
+		return nil, false
+	}
+
+	copy := map[string]any{}
+	for key, value := range stored {
+		copy[key] = value
+	}
+	return copy, true
+}
+
+func (api *fakeAPI) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	api.requests = append(api.requests, fakeRequest{Method: r.Method, Path: r.URL.Path, Body: string(body)})
+
+	switch {
+	case r.URL.Path == "/oauth/token/":
+		api.write(w, http.StatusOK, map[string]any{"status": 200, "lifetime": 3600, "token": "token"})
+		return
+	case r.Header.Get("cdn-auth-token") != "token":
+		api.write(w, http.StatusUnauthorized, map[string]any{"status": "error", "message": "unauthorized"})
+		return
+	case strings.HasPrefix(r.URL.Path, "/api/account/task/"):
+		api.handleTask(w, strings.TrimPrefix(r.URL.Path, "/api/account/task/"))
+		return
+	case strings.HasPrefix(r.URL.Path, "/api/account/resource/http/"):
+		api.handleResource(w, r.Method, strings.TrimPrefix(r.URL.Path, "/api/account/resource/http/"), body)
+		return
+	}
+
+	api.write(w, http.StatusNotFound, map[string]any{"status": "error", "message": "unknown path " + r.URL.Path})
+}
+
+func (api *fakeAPI) handleTask(w http.ResponseWriter, task_id string) {
+	poll := api.taskPolls[task_id]
+	api.taskPolls[task_id] = poll + 1
+	if poll >= len(api.taskStatuses) {
+		poll = len(api.taskStatuses) - 1
+	}
+
+	api.write(w, http.StatusOK, map[string]any{"id": task_id, "status": api.taskStatuses[poll], "message": "task " + api.taskStatuses[poll]})
+}
+
+func (api *fakeAPI) handleResource(w http.ResponseWriter, method, id string, body []byte) {
+	request := map[string]any{}
+	if len(body) > 0 {
+		var ordered struct {
+			Locations configuration.LocationList `json:"locations"`
+		}
+		err := json.Unmarshal(body, &request)
+		if err == nil {
+			err = json.Unmarshal(body, &ordered)
+		}
+		if err != nil {
+			api.write(w, http.StatusBadRequest, map[string]any{"status": "error", "message": err.Error()})
+			return
+		}
+		if locations, ok := request["locations"].(map[string]any); ok {
+			request["locations"] = &fakeLocations{keys: ordered.Locations.Keys(), values: locations}
+		}
+	}
+
+	if id == "" {
+		switch method {
+		case http.MethodGet:
+			resources := []map[string]any{}
+			for i := 1; i <= api.next_id; i++ {
+				if stored, ok := api.resources[fmt.Sprint(i)]; ok {
+					resources = append(resources, stored)
+				}
+			}
+			api.write(w, http.StatusOK, resources)
+		case http.MethodPost:
+			id := api.store(request)
+			api.write(w, http.StatusOK, map[string]any{"status": "accept", "task_id": "task-" + id, "resource_id": id})
+		default:
+			api.write(w, http.StatusMethodNotAllowed, nil)
+		}
+		return
+	}
+
+	stored, ok := api.resources[id]
+	if !ok {
+		api.write(w, http.StatusNotFound, map[string]any{"status": "error", "message": "resource not found"})
+		return
+	}
+
+	switch method {
+	case http.MethodGet:
+		if api.failReads {
+			api.write(w, http.StatusForbidden, map[string]any{"status": "error", "message": "forbidden"})
+			return
+		}
+		api.write(w, http.StatusOK, stored)
+		return
+	case http.MethodPut:
+		replaced := map[string]any{"id": id, "cdn_domain": stored["cdn_domain"], "creation_ts": stored["creation_ts"], "active": stored["active"]}
+		for key, value := range request {
+			replaced[key] = value
+		}
+		if api.normalize != nil {
+			api.normalize(replaced)
+		}
+		api.resources[id] = replaced
+	case http.MethodPatch:
+		applyMergePatch(stored, request)
+		if api.normalize != nil {
+			api.normalize(stored)
+		}
+	case http.MethodDelete:
+		delete(api.resources, id)
+	default:
+		api.write(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+
+	api.write(w, http.StatusOK, map[string]any{"status": "accept", "task_id": fmt.Sprintf("task-%s-%d", id, len(api.requests))})
+}
+
+// fakeLocations are the locations of a resource, an object whose keys are
+// kept in order like the api does.
+type fakeLocations struct {
+	keys   []string
+	values map[string]any
+}
+
+func (l *fakeLocations) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range l.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		encoded_key, _ := json.Marshal(key)
+		encoded_value, err := json.Marshal(l.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encoded_key)
+		buffer.WriteByte(':')
+		buffer.Write(encoded_value)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// merge applies a merge patch of locations. When the patch lists every
+// location its order is taken, otherwise new locations are added at the end.
+func (l *fakeLocations) merge(patch *fakeLocations) *fakeLocations {
+	if l == nil {
+		l = &fakeLocations{values: map[string]any{}}
+	}
+	merged := &fakeLocations{values: map[string]any{}}
+	for key, value := range l.values {
+		merged.values[key] = value
+	}
+	for _, key := range patch.keys {
+		patch_object, ok := patch.values[key].(map[string]any)
+		if !ok {
+			delete(merged.values, key)
+			continue
+		}
+		target_object, _ := merged.values[key].(map[string]any)
+		if target_object == nil {
+			target_object = map[string]any{}
+		}
+		applyMergePatch(target_object, patch_object)
+		merged.values[key] = target_object
+	}
+
+	keys := append([]string{}, patch.keys...)
+	for _, key := range l.keys {
+		if _, ok := patch.values[key]; !ok {
+			keys = nil
+			break
+		}
+	}
+	if keys == nil {
+		keys = append(append([]string{}, l.keys...), patch.keys...)
+	}
+	for _, key := range keys {
+		if _, ok := merged.values[key]; ok && !slices.Contains(merged.keys, key) {
+			merged.keys = append(merged.keys, key)
+		}
+	}
+
+	return merged
+}
+
+// applyMergePatch merges patch into target as described in RFC 7396.
+func applyMergePatch(target, patch map[string]any) {
+	for key, value := range patch {
+		patch_object, ok := value.(map[string]any)
+		patch_locations, is_locations := value.(*fakeLocations)
+		switch {
+		case value == nil:
+			delete(target, key)
+		case is_locations:
+			target_locations, _ := target[key].(*fakeLocations)
+			target[key] = target_locations.merge(patch_locations)
+		case ok:
+			target_object, ok := target[key].(map[string]any)
+			if !ok {
+				target_object = map[string]any{}
+			}
+			applyMergePatch(target_object, patch_object)
+			target[key] = target_object
+		default:
+			target[key] = value
+		}
+	}
+}
+
+func (api *fakeAPI) write(w http.ResponseWriter, status int, body any) {
+	w.WriteHeader(status)
+	if body != nil {
+		json.NewEncoder(w).Encode(body)
+	}
+}
+
+// httpResourceSchema returns the schema of the cdnvideo_http resource.
+func httpResourceSchema(t *testing.T) resource.SchemaResponse {
+	schema_response := resource.SchemaResponse{}
+	NewHTTPResource().Schema(context.Background(), resource.SchemaRequest{}, &schema_response)
+	if schema_response.Diagnostics.HasError() {
+		t.Fatalf("schema: %v", schema_response.Diagnostics)
+	}
+	return schema_response
+}
+
+// testModel returns a resource model for the given api representation with
+// all provider settings at their defaults, as Terraform would plan it.
+func testModel(t *testing.T, http_resource configuration.CdnHttpResource) CdnHttpResourceModel {
+	model, diags := GenerateState(http_resource, context.Background())
+	if diags.HasError() {
+		t.Fatalf("GenerateState: %v", diags)
+	}
+	model.WaitForDeployment = types.BoolValue(true)
+	model.RemoveDeactivated = types.BoolValue(false)
+	model.DeletionMode = types.StringValue(deletionModeDeactivate)
+	model.DeletionProtection = types.BoolValue(false)
+	model.AdoptExisting = types.BoolValue(false)
+	model.ForceOverwrite = types.BoolValue(false)
+
+	return model
+}
+
+// testPlan converts a model into a plan with unknown computed attributes.
+func testPlan(t *testing.T, model CdnHttpResourceModel) tfsdk.Plan {
+	state := testState(t, model)
+	return tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+}
+
+// testConfig converts a model into a configuration, as passed to Create and
+// Update next to the plan.
+func testConfig(t *testing.T, model CdnHttpResourceModel) tfsdk.Config {
+	state := testState(t, model)
+	return tfsdk.Config{Schema: state.Schema, Raw: state.Raw}
+}
+
+// testState converts a model into a state.
+func testState(t *testing.T, model CdnHttpResourceModel) tfsdk.State {
+	schema := httpResourceSchema(t).Schema
+	state := tfsdk.State{Schema: schema, Raw: tftypes.NewValue(schema.Type().TerraformType(context.Background()), nil)}
+	if diags := state.Set(context.Background(), model); diags.HasError() {
+		t.Fatalf("state.Set: %v", diags)
+	}
+	return state
+}
+
+// emptyState returns a null state as passed to Create.
+func emptyState(t *testing.T) tfsdk.State {
+	schema := httpResourceSchema(t).Schema
+	return tfsdk.State{Schema: schema, Raw: tftypes.NewValue(schema.Type().TerraformType(context.Background()), nil)}
+}
+
+// stateModel decodes a state into a model.
+func stateModel(t *testing.T, state tfsdk.State) CdnHttpResourceModel {
+	var model CdnHttpResourceModel
+	if diags := state.Get(context.Background(), &model); diags.HasError() {
+		t.Fatalf("state.Get: %v", diags)
+	}
+	return model
+}
@@ -3,15 +3,18 @@ package provider
 import (
 	"context"
 	"os"
+	"time"
 
 	"terraform-provider-cdnvideo/internal/configuration"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
@@ -25,6 +28,18 @@ type cdnvideoProviderModel struct {
 	AccountName types.String `tfsdk:"account_name"`
 	Username    types.String `tfsdk:"username"`
 	Password    types.String `tfsdk:"password"`
+	ApiToken    types.String `tfsdk:"api_token"`
+	ApiURL      types.String `tfsdk:"api_url"`
+	OauthURL    types.String `tfsdk:"oauth_url"`
+	Retry       types.Object `tfsdk:"retry"`
+}
+
+// retryModel maps the provider retry block.
+type retryModel struct {
+	MaxAttempts types.Int64  `tfsdk:"max_attempts"`
+	BaseBackoff types.String `tfsdk:"base_backoff"`
+	MaxBackoff  types.String `tfsdk:"max_backoff"`
+	Jitter      types.Bool   `tfsdk:"jitter"`
 }
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -64,6 +79,42 @@ func (p *cdnvideoProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 				Optional:  true,
 				Sensitive: true,
 			},
+			"api_token": schema.StringAttribute{
+				Description: "Static CDNVideo API token used instead of username and password",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"api_url": schema.StringAttribute{
+				Description: "Base URL of the CDNVideo configuration API. Defaults to " + configuration.DefaultApiURL,
+				Optional:    true,
+			},
+			"oauth_url": schema.StringAttribute{
+				Description: "URL of the CDNVideo OAuth token endpoint. Defaults to " + configuration.DefaultOauthURL,
+				Optional:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"retry": schema.SingleNestedBlock{
+				Description: "Retry settings for transient API failures (rate limiting, 502, 503, 504 and network errors)",
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						Description: "Total number of attempts per request including the first one. Set to 1 to disable retries. Defaults to 4",
+						Optional:    true,
+					},
+					"base_backoff": schema.StringAttribute{
+						Description: "Delay before the first retry, doubled on each next one, e.g. \"1s\". Defaults to 1s",
+						Optional:    true,
+					},
+					"max_backoff": schema.StringAttribute{
+						Description: "Upper bound for the delay between retries, including delays requested by the API with a Retry-After header, e.g. \"30s\". Defaults to 30s",
+						Optional:    true,
+					},
+					"jitter": schema.BoolAttribute{
+						Description: "Randomize delays between retries. Defaults to true",
+						Optional:    true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -109,6 +160,33 @@ func (p *cdnvideoProvider) Configure(ctx context.Context, req provider.Configure
 		)
 	}
 
+	if config.ApiToken.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_token"),
+			"Unknown CDNVideo API token",
+			"The provider cannot create the CDNVideo API client as there is an unknown configuration value for the CDNVideo API api_token. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the CDN_API_TOKEN environment variable.",
+		)
+	}
+
+	if config.ApiURL.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_url"),
+			"Unknown CDNVideo API URL",
+			"The provider cannot create the CDNVideo API client as there is an unknown configuration value for the CDNVideo API api_url. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the CDN_API_URL environment variable.",
+		)
+	}
+
+	if config.OauthURL.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("oauth_url"),
+			"Unknown CDNVideo OAuth URL",
+			"The provider cannot create the CDNVideo API client as there is an unknown configuration value for the CDNVideo API oauth_url. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the CDN_OAUTH_URL environment variable.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -119,6 +197,9 @@ func (p *cdnvideoProvider) Configure(ctx context.Context, req provider.Configure
 	account_name := os.Getenv("CDN_ACCOUNT_NAME")
 	username := os.Getenv("CDN_USERNAME")
 	password := os.Getenv("CDN_PASSWORD")
+	api_token := os.Getenv("CDN_API_TOKEN")
+	api_url := os.Getenv("CDN_API_URL")
+	oauth_url := os.Getenv("CDN_OAUTH_URL")
 
 	if !config.AccountName.IsNull() {
 		account_name = config.AccountName.ValueString()
@@ -132,39 +213,59 @@ func (p *cdnvideoProvider) Configure(ctx context.Context, req provider.Configure
 		password = config.Password.ValueString()
 	}
 
+	if !config.ApiToken.IsNull() {
+		api_token = config.ApiToken.ValueString()
+	}
+
+	if !config.ApiURL.IsNull() {
+		api_url = config.ApiURL.ValueString()
+	}
+
+	if !config.OauthURL.IsNull() {
+		oauth_url = config.OauthURL.ValueString()
+	}
+
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
-	if account_name == "" {
+	if api_token != "" && (username != "" || password != "") {
 		resp.Diagnostics.AddAttributeError(
-			path.Root("account_name"),
-			"Missing CDNVideo API AccountName",
-			"The provider cannot create the CDNVideo API client as there is a missing or empty value for the CDNVideo API account_name. "+
-				"Set the account_name value in the configuration or use the CDN_ACCOUNT_NAME environment variable. "+
-				"If either is already set, ensure the value is not empty.",
+			path.Root("api_token"),
+			"Conflicting CDNVideo API credentials",
+			"The provider cannot create the CDNVideo API client as both api_token and username/password are set. "+
+				"Use either the api_token value (or the CDN_API_TOKEN environment variable), "+
+				"or the username and password values (or the CDN_USERNAME and CDN_PASSWORD environment variables), but not both.",
 		)
 	}
 
-	if username == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("username"),
-			"Missing CDNVideo API Username",
-			"The provider cannot create the CDNVideo API client as there is a missing or empty value for the CDNVideo API username. "+
-				"Set the username value in the configuration or use the CDN_USERNAME environment variable. "+
-				"If either is already set, ensure the value is not empty.",
-		)
-	}
+	// A missing account name or missing credentials are reported by the first
+	// API call, so configurations that do not manage any cdnvideo resources can
+	// be planned without them.
+	if api_token == "" && (username != "" || password != "") {
+		if username == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("username"),
+				"Missing CDNVideo API Username",
+				"The provider cannot create the CDNVideo API client as there is a missing or empty value for the CDNVideo API username. "+
+					"Set the username value in the configuration or use the CDN_USERNAME environment variable. "+
+					"If either is already set, ensure the value is not empty.",
+			)
+		}
 
-	if password == "" {
-		resp.Diagnostics.AddAttributeError(
-			path.Root("password"),
-			"Missing CDNVideo API Password",
-			"The provider cannot create the CDNVideo API client as there is a missing or empty value for the CDNVideo API password. "+
-				"Set the password value in the configuration or use the CDN_PASSWORD environment variable. "+
-				"If either is already set, ensure the value is not empty.",
-		)
+		if password == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("password"),
+				"Missing CDNVideo API Password",
+				"The provider cannot create the CDNVideo API client as there is a missing or empty value for the CDNVideo API password. "+
+					"Set the password value in the configuration or use the CDN_PASSWORD environment variable. "+
+					"If either is already set, ensure the value is not empty.",
+			)
+		}
 	}
 
+	retry, diags := retryConfig(ctx, config.Retry)
+	resp.Diagnostics.Append(diags...)
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -172,12 +273,24 @@ func (p *cdnvideoProvider) Configure(ctx context.Context, req provider.Configure
 	ctx = tflog.SetField(ctx, "cdn_account_name", account_name)
 	ctx = tflog.SetField(ctx, "cdn_username", username)
 	ctx = tflog.SetField(ctx, "cdn_password", password)
-	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "cdn_password")
+	ctx = tflog.SetField(ctx, "cdn_api_token", api_token)
+	ctx = tflog.SetField(ctx, "cdn_api_url", api_url)
+	ctx = tflog.SetField(ctx, "cdn_oauth_url", oauth_url)
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "cdn_password", "cdn_api_token")
 
 	tflog.Debug(ctx, "Creating CDNVideo client")
 
-	// Create a new CDNVideo client using the configuration values
-	configuration_proxy, err := configuration.NewProxy(&username, &password, &account_name)
+	// Create a new CDNVideo client using the configuration values. The client
+	// authenticates lazily on the first API call.
+	configuration_proxy, err := configuration.NewProxy(ctx, configuration.ProxyConfig{
+		Username:    username,
+		Password:    password,
+		Token:       api_token,
+		AccountName: account_name,
+		ApiURL:      api_url,
+		OauthURL:    oauth_url,
+		Retry:       retry,
+	})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Create CDNVideo API Client",
@@ -195,6 +308,70 @@ func (p *cdnvideoProvider) Configure(ctx context.Context, req provider.Configure
 	tflog.Info(ctx, "Configured success client", map[string]any{"success": true})
 }
 
+// retryConfig converts the provider retry block into client retry settings,
+// keeping defaults for everything that is not set.
+func retryConfig(ctx context.Context, retry_object types.Object) (configuration.RetryConfig, diag.Diagnostics) {
+	retry := configuration.DefaultRetryConfig()
+	if retry_object.IsNull() || retry_object.IsUnknown() {
+		return retry, nil
+	}
+
+	var model retryModel
+	diags := retry_object.As(ctx, &model, basetypes.ObjectAsOptions{})
+	if diags.HasError() {
+		return retry, diags
+	}
+
+	if !model.MaxAttempts.IsNull() && !model.MaxAttempts.IsUnknown() {
+		if model.MaxAttempts.ValueInt64() < 1 {
+			diags.AddAttributeError(
+				path.Root("retry").AtName("max_attempts"),
+				"Invalid retry max_attempts",
+				"The retry max_attempts value must be at least 1.",
+			)
+		}
+		retry.MaxAttempts = int(model.MaxAttempts.ValueInt64())
+	}
+
+	if !model.BaseBackoff.IsNull() && !model.BaseBackoff.IsUnknown() {
+		backoff, err := time.ParseDuration(model.BaseBackoff.ValueString())
+		if err != nil || backoff < 0 {
+			diags.AddAttributeError(
+				path.Root("retry").AtName("base_backoff"),
+				"Invalid retry base_backoff",
+				"The retry base_backoff value must be a non-negative duration such as \"500ms\" or \"2s\".",
+			)
+		}
+		retry.BaseBackoff = backoff
+	}
+
+	if !model.MaxBackoff.IsNull() && !model.MaxBackoff.IsUnknown() {
+		backoff, err := time.ParseDuration(model.MaxBackoff.ValueString())
+		if err != nil || backoff < 0 {
+			diags.AddAttributeError(
+				path.Root("retry").AtName("max_backoff"),
+				"Invalid retry max_backoff",
+				"The retry max_backoff value must be a non-negative duration such as \"10s\" or \"1m\".",
+			)
+		}
+		retry.MaxBackoff = backoff
+	}
+
+	if !model.Jitter.IsNull() && !model.Jitter.IsUnknown() {
+		retry.Jitter = model.Jitter.ValueBool()
+	}
+
+	if !diags.HasError() && retry.MaxBackoff < retry.BaseBackoff {
+		diags.AddAttributeError(
+			path.Root("retry").AtName("max_backoff"),
+			"Invalid retry max_backoff",
+			"The retry max_backoff value must not be less than base_backoff.",
+		)
+	}
+
+	return retry, diags
+}
+
 // DataSources defines the data sources implemented in the provider.
 func (p *cdnvideoProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return nil
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testProviderConfig builds a provider configuration from the given
+// attribute values, leaving all other attributes null.
+func testProviderConfig(t *testing.T, values map[string]tftypes.Value) tfsdk.Config {
+	ctx := context.Background()
+
+	schema_response := provider.SchemaResponse{}
+	New("test")().Schema(ctx, provider.SchemaRequest{}, &schema_response)
+
+	object_type := schema_response.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	attributes := map[string]tftypes.Value{}
+	for name, attribute_type := range object_type.AttributeTypes {
+		if value, ok := values[name]; ok {
+			attributes[name] = value
+		} else {
+			attributes[name] = tftypes.NewValue(attribute_type, nil)
+		}
+	}
+
+	return tfsdk.Config{Schema: schema_response.Schema, Raw: tftypes.NewValue(object_type, attributes)}
+}
+
+// configureTestProvider runs Configure with a clean environment.
+func configureTestProvider(t *testing.T, values map[string]tftypes.Value) provider.ConfigureResponse {
+	for _, env := range []string{"CDN_ACCOUNT_NAME", "CDN_USERNAME", "CDN_PASSWORD", "CDN_API_TOKEN", "CDN_API_URL", "CDN_OAUTH_URL"} {
+		t.Setenv(env, "")
+	}
+
+	resp := provider.ConfigureResponse{}
+	New("test")().Configure(context.Background(), provider.ConfigureRequest{Config: testProviderConfig(t, values)}, &resp)
+	return resp
+}
+
+func TestConfigureStaticToken(t *testing.T) {
+	api := newFakeAPI(t)
+
+	resp := configureTestProvider(t, map[string]tftypes.Value{
+		"account_name": tftypes.NewValue(tftypes.String, "account"),
+		"api_token":    tftypes.NewValue(tftypes.String, "token"),
+		"api_url":      tftypes.NewValue(tftypes.String, api.server.URL+"/api"),
+		"oauth_url":    tftypes.NewValue(tftypes.String, api.server.URL+"/oauth/token/"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure: %v", resp.Diagnostics)
+	}
+
+	proxy := resp.ResourceData.(*configuration.ConfigurationApiProxy)
+	if proxy.Auth.Token != "token" {
+		t.Errorf("expected static token, got %q", proxy.Auth.Token)
+	}
+	for _, request := range api.requests {
+		if request.Path == "/oauth/token/" {
+			t.Error("static token must not be exchanged")
+		}
+	}
+}
+
+func TestConfigureCredentialsValidation(t *testing.T) {
+	cases := map[string]struct {
+		values  map[string]tftypes.Value
+		summary string
+	}{
+		"both": {
+			values: map[string]tftypes.Value{
+				"account_name": tftypes.NewValue(tftypes.String, "account"),
+				"api_token":    tftypes.NewValue(tftypes.String, "token"),
+				"username":     tftypes.NewValue(tftypes.String, "user"),
+				"password":     tftypes.NewValue(tftypes.String, "pass"),
+			},
+			summary: "Conflicting CDNVideo API credentials",
+		},
+		"password only": {
+			values: map[string]tftypes.Value{
+				"account_name": tftypes.NewValue(tftypes.String, "account"),
+				"password":     tftypes.NewValue(tftypes.String, "pass"),
+			},
+			summary: "Missing CDNVideo API Username",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := configureTestProvider(t, c.values)
+
+			summaries := []string{}
+			for _, diagnostic := range resp.Diagnostics.Errors() {
+				summaries = append(summaries, diagnostic.Summary())
+			}
+			if !strings.Contains(strings.Join(summaries, "\n"), c.summary) {
+				t.Errorf("expected %q diagnostic, got %v", c.summary, summaries)
+			}
+		})
+	}
+}
+
+func TestConfigureWithoutCredentials(t *testing.T) {
+	resp := configureTestProvider(t, map[string]tftypes.Value{
+		"account_name": tftypes.NewValue(tftypes.String, "account"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure: %v", resp.Diagnostics)
+	}
+
+	// Missing credentials only fail actual api calls
+	proxy := resp.ResourceData.(*configuration.ConfigurationApiProxy)
+	if _, err := proxy.GetHttpResources(context.Background()); !errors.Is(err, configuration.ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestConfigureWithoutAccountName(t *testing.T) {
+	resp := configureTestProvider(t, map[string]tftypes.Value{
+		"api_token": tftypes.NewValue(tftypes.String, "token"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure: %v", resp.Diagnostics)
+	}
+
+	proxy := resp.ResourceData.(*configuration.ConfigurationApiProxy)
+	if _, err := proxy.GetHttpResources(context.Background()); !errors.Is(err, configuration.ErrNoAccountName) {
+		t.Errorf("expected ErrNoAccountName, got %v", err)
+	}
+}
+
+func TestConfigureDoesNotAuthenticate(t *testing.T) {
+	api := newFakeAPI(t)
+
+	resp := configureTestProvider(t, map[string]tftypes.Value{
+		"account_name": tftypes.NewValue(tftypes.String, "account"),
+		"username":     tftypes.NewValue(tftypes.String, "user"),
+		"password":     tftypes.NewValue(tftypes.String, "pass"),
+		"api_url":      tftypes.NewValue(tftypes.String, api.server.URL+"/api"),
+		"oauth_url":    tftypes.NewValue(tftypes.String, api.server.URL+"/oauth/token/"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Configure: %v", resp.Diagnostics)
+	}
+	if len(api.requests) != 0 {
+		t.Errorf("expected no api requests during Configure, got %v", api.requests)
+	}
+}
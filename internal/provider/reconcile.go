@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// reconcileValue returns the actual value read from the api with every part
+// that is semantically equal to the planned value replaced by the planned one.
+// Terraform rejects a new state that differs from known planned values, so
+// server side normalization such as empty objects for omitted blocks, reordered
+// sets or trimmed strings must not leak into the state. Real differences and
+// values that were unknown in the plan are taken from the api.
+func reconcileValue(planned, actual tftypes.Value) tftypes.Value {
+	if planned.Type() == nil || !planned.IsKnown() || !planned.Type().Equal(actual.Type()) {
+		return actual
+	}
+	if planned.Equal(actual) {
+		return planned
+	}
+	if planned.IsNull() || actual.IsNull() {
+		if isEmptyValue(planned) && isEmptyValue(actual) {
+			return planned
+		}
+		return actual
+	}
+
+	value_type := planned.Type()
+	switch {
+	case value_type.Is(tftypes.Object{}), value_type.Is(tftypes.Map{}):
+		var planned_elements, actual_elements map[string]tftypes.Value
+		if planned.As(&planned_elements) != nil || actual.As(&actual_elements) != nil || len(planned_elements) != len(actual_elements) {
+			return actual
+		}
+
+		reconciled := make(map[string]tftypes.Value, len(actual_elements))
+		for key, element := range actual_elements {
+			planned_element, ok := planned_elements[key]
+			if !ok {
+				return actual
+			}
+			reconciled[key] = reconcileValue(planned_element, element)
+		}
+		return tftypes.NewValue(value_type, reconciled)
+
+	case value_type.Is(tftypes.List{}), value_type.Is(tftypes.Tuple{}):
+		var planned_elements, actual_elements []tftypes.Value
+		if planned.As(&planned_elements) != nil || actual.As(&actual_elements) != nil || len(planned_elements) != len(actual_elements) {
+			return actual
+		}
+
+		reconciled := make([]tftypes.Value, len(actual_elements))
+		for i, element := range actual_elements {
+			reconciled[i] = reconcileValue(planned_elements[i], element)
+		}
+		return tftypes.NewValue(value_type, reconciled)
+
+	case value_type.Is(tftypes.Set{}):
+		var planned_elements, actual_elements []tftypes.Value
+		if planned.As(&planned_elements) != nil || actual.As(&actual_elements) != nil || len(planned_elements) != len(actual_elements) {
+			return actual
+		}
+
+		// Sets are unordered, every actual element needs an equal planned one
+		for _, element := range actual_elements {
+			found := false
+			for _, planned_element := range planned_elements {
+				if reconcileValue(planned_element, element).Equal(planned_element) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return actual
+			}
+		}
+		return planned
+
+	case value_type.Is(tftypes.String):
+		var planned_string, actual_string string
+		if planned.As(&planned_string) == nil && actual.As(&actual_string) == nil &&
+			strings.TrimSpace(planned_string) == strings.TrimSpace(actual_string) {
+			return planned
+		}
+	}
+
+	return actual
+}
+
+// isEmptyValue reports whether value is null, an empty collection or an
+// object without any non-empty attribute, which the api treats alike.
+func isEmptyValue(value tftypes.Value) bool {
+	if value.IsNull() {
+		return true
+	}
+	if !value.IsKnown() {
+		return false
+	}
+
+	value_type := value.Type()
+	switch {
+	case value_type.Is(tftypes.Object{}), value_type.Is(tftypes.Map{}):
+		var elements map[string]tftypes.Value
+		if value.As(&elements) != nil {
+			return false
+		}
+		if value_type.Is(tftypes.Map{}) {
+			return len(elements) == 0
+		}
+		for _, element := range elements {
+			if !isEmptyValue(element) {
+				return false
+			}
+		}
+		return true
+
+	case value_type.Is(tftypes.List{}), value_type.Is(tftypes.Set{}), value_type.Is(tftypes.Tuple{}):
+		var elements []tftypes.Value
+		if value.As(&elements) != nil {
+			return false
+		}
+		return len(elements) == 0
+	}
+
+	return false
+}
+
+// nullUnknownValues returns value with every unknown part replaced by null.
+// Attributes computed by the api are unknown in the plan until their first
+// read, they are left out of api requests so that the api fills them in.
+func nullUnknownValues(value tftypes.Value) tftypes.Value {
+	known, err := tftypes.Transform(value, func(_ *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+		if !value.IsKnown() {
+			return tftypes.NewValue(value.Type(), nil), nil
+		}
+		return value, nil
+	})
+	if err != nil {
+		return value
+	}
+	return known
+}
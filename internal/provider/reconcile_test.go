@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestReconcileValue(t *testing.T) {
+	object_type := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"name":  tftypes.String,
+		"port":  tftypes.Number,
+		"names": tftypes.Set{ElementType: tftypes.String},
+		"cache": tftypes.Object{AttributeTypes: map[string]tftypes.Type{"disable": tftypes.Bool}},
+	}}
+	names := func(values ...string) tftypes.Value {
+		elements := []tftypes.Value{}
+		for _, value := range values {
+			elements = append(elements, tftypes.NewValue(tftypes.String, value))
+		}
+		return tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, elements)
+	}
+	cache := func(disable any) tftypes.Value {
+		return tftypes.NewValue(object_type.AttributeTypes["cache"], map[string]tftypes.Value{"disable": tftypes.NewValue(tftypes.Bool, disable)})
+	}
+	object := func(name string, port any, names, cache tftypes.Value) tftypes.Value {
+		return tftypes.NewValue(object_type, map[string]tftypes.Value{
+			"name":  tftypes.NewValue(tftypes.String, name),
+			"port":  tftypes.NewValue(tftypes.Number, port),
+			"names": names,
+			"cache": cache,
+		})
+	}
+	null_cache := tftypes.NewValue(object_type.AttributeTypes["cache"], nil)
+
+	cases := map[string]struct {
+		planned, actual, expected tftypes.Value
+	}{
+		"equivalent": {
+			planned:  object(" name", 80, names("a", "b"), null_cache),
+			actual:   object("name", 80, names("b", "a"), cache(nil)),
+			expected: object(" name", 80, names("a", "b"), null_cache),
+		},
+		"unknown taken from api": {
+			planned:  object("name", tftypes.UnknownValue, names(), null_cache),
+			actual:   object("name", 443, names(), null_cache),
+			expected: object("name", 443, names(), null_cache),
+		},
+		"real differences kept": {
+			planned:  object("name", 80, names("a"), null_cache),
+			actual:   object("other", 80, names("b"), cache(true)),
+			expected: object("other", 80, names("b"), cache(true)),
+		},
+	}
+
+	for name, c := range cases {
+		if reconciled := reconcileValue(c.planned, c.actual); !reconciled.Equal(c.expected) {
+			t.Errorf("%s: expected %s, got %s", name, c.expected, reconciled)
+		}
+	}
+}
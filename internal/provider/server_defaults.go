@@ -0,0 +1,292 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// configuredAttributesKey is the private state key holding the
+// configuredAttributes of a resource.
+const configuredAttributesKey = "configured_attributes"
+
+// configuredAttributes are the computed attributes that were set in the
+// configuration when the resource was last applied, all others hold the
+// defaults the api filled in. Locations are keyed by their api key rather than
+// their position, e.g. "location[= /].cache".
+type configuredAttributes map[string]bool
+
+// recordConfiguredAttributes returns the computed attributes set in config.
+func recordConfiguredAttributes(ctx context.Context, config tfsdk.Config) configuredAttributes {
+	configured := configuredAttributes{}
+
+	var record func(attribute_path *tftypes.AttributePath, key string, value tftypes.Value)
+	record = func(attribute_path *tftypes.AttributePath, key string, value tftypes.Value) {
+		var attributes map[string]tftypes.Value
+		if !value.IsKnown() || value.IsNull() || !value.Type().Is(tftypes.Object{}) || value.As(&attributes) != nil {
+			return
+		}
+		for name, attribute_value := range attributes {
+			attribute_path := attribute_path.WithAttributeName(name)
+			attribute, err := config.Schema.AttributeAtTerraformPath(ctx, attribute_path)
+			if err != nil {
+				continue
+			}
+			if !attribute_value.IsNull() && attribute.IsComputed() {
+				configured[key+name] = true
+			}
+			if key == "" && name == "location" {
+				var locations []tftypes.Value
+				if !attribute_value.IsKnown() || attribute_value.As(&locations) != nil {
+					continue
+				}
+				for i, location := range locations {
+					if location_key, ok := locationKey(location); ok {
+						record(attribute_path.WithElementKeyInt(i), "location["+location_key+"].", location)
+					}
+				}
+				continue
+			}
+			record(attribute_path, key+name+".", attribute_value)
+		}
+	}
+	record(tftypes.NewAttributePath(), "", config.Raw)
+
+	return configured
+}
+
+// decodeConfiguredAttributes decodes configured attributes recorded in
+// private state, none are recorded before the first apply or after an import.
+func decodeConfiguredAttributes(data []byte) configuredAttributes {
+	var keys []string
+	configured := configuredAttributes{}
+	if len(data) == 0 || json.Unmarshal(data, &keys) != nil {
+		return configured
+	}
+	for _, key := range keys {
+		configured[key] = true
+	}
+	return configured
+}
+
+// encode returns configured as recorded in private state.
+func (configured configuredAttributes) encode() []byte {
+	keys := make([]string, 0, len(configured))
+	for key := range configured {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	data, _ := json.Marshal(keys)
+	return data
+}
+
+// locationKey returns the api key of a location value, from its path and
+// match attributes.
+func locationKey(location tftypes.Value) (string, bool) {
+	var attributes map[string]tftypes.Value
+	if !location.IsKnown() || location.IsNull() || location.As(&attributes) != nil {
+		return "", false
+	}
+
+	var location_path, match string
+	if !attributes["path"].IsKnown() || attributes["path"].IsNull() || attributes["path"].As(&location_path) != nil {
+		return "", false
+	}
+	if !attributes["match"].IsKnown() {
+		return "", false
+	}
+	if attributes["match"].IsNull() {
+		match = configuration.LocationMatchPrefix
+	} else if attributes["match"].As(&match) != nil {
+		return "", false
+	}
+	return configuration.Location{Path: location_path, Match: &match}.Key(), true
+}
+
+// locationIndex returns the index of the location with key in locations.
+func locationIndex(locations tftypes.Value, key string) (int, bool) {
+	var elements []tftypes.Value
+	if !locations.IsKnown() || locations.IsNull() || locations.As(&elements) != nil {
+		return 0, false
+	}
+	for i, location := range elements {
+		if location_key, ok := locationKey(location); ok && location_key == key {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// valueAt returns the part of value at attribute_path, which may only hold
+// attribute names and list indexes.
+func valueAt(value tftypes.Value, attribute_path path.Path) (tftypes.Value, bool) {
+	for _, step := range attribute_path.Steps() {
+		if !value.IsKnown() || value.IsNull() {
+			return tftypes.Value{}, false
+		}
+		switch step := step.(type) {
+		case path.PathStepAttributeName:
+			var attributes map[string]tftypes.Value
+			if value.As(&attributes) != nil {
+				return tftypes.Value{}, false
+			}
+			attribute, ok := attributes[string(step)]
+			if !ok {
+				return tftypes.Value{}, false
+			}
+			value = attribute
+		case path.PathStepElementKeyInt:
+			var elements []tftypes.Value
+			if value.As(&elements) != nil || int(step) < 0 || int(step) >= len(elements) {
+				return tftypes.Value{}, false
+			}
+			value = elements[step]
+		default:
+			return tftypes.Value{}, false
+		}
+	}
+	return value, true
+}
+
+// privateState is the private state passed to plan modifiers.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// useServerDefault returns a plan modifier for attributes the api fills in
+// with a default when left out of the configuration. Like UseStateForUnknown
+// it keeps the prior value of such attributes, but only if it is a default:
+// once an attribute that was configured is removed from the configuration its
+// value is planned as unknown, so that the api resets it. Locations are
+// matched with their prior state by key, not by position.
+func useServerDefault() serverDefaultModifier {
+	return serverDefaultModifier{}
+}
+
+type serverDefaultModifier struct{}
+
+func (m serverDefaultModifier) Description(_ context.Context) string {
+	return "Once set, the value of this attribute in state will not change unless it is configured or its configuration is removed."
+}
+
+func (m serverDefaultModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+// priorValue returns the prior value of the attribute at attribute_path when
+// it is a server default, or reset when it has to be planned as unknown. A
+// null prior value is not returned, the plan is left as it is then.
+func (m serverDefaultModifier) priorValue(ctx context.Context, attribute_path path.Path, plan tfsdk.Plan, state tfsdk.State, private privateState) (value attr.Value, reset bool, diags diag.Diagnostics) {
+	recorded, diags := private.GetKey(ctx, configuredAttributesKey)
+	if diags.HasError() {
+		return nil, false, diags
+	}
+	configured := decodeConfiguredAttributes(recorded)
+
+	state_path := path.Empty()
+	key := ""
+	for _, step := range attribute_path.Steps() {
+		switch step := step.(type) {
+		case path.PathStepAttributeName:
+			state_path = state_path.AtName(string(step))
+			key += string(step) + "."
+		case path.PathStepElementKeyInt:
+			planned_location, _ := valueAt(plan.Raw, state_path.AtListIndex(int(step)))
+			location_key, ok := locationKey(planned_location)
+			if !ok {
+				return nil, true, diags
+			}
+			locations, _ := valueAt(state.Raw, state_path)
+			index, ok := locationIndex(locations, location_key)
+			if !ok {
+				return nil, true, diags
+			}
+			state_path = state_path.AtListIndex(index)
+			key = strings.TrimSuffix(key, ".") + "[" + location_key + "]."
+		default:
+			return nil, true, diags
+		}
+	}
+	if configured[strings.TrimSuffix(key, ".")] {
+		return nil, true, diags
+	}
+
+	prior, ok := valueAt(state.Raw, state_path)
+	if !ok {
+		return nil, true, diags
+	}
+	if prior.IsNull() {
+		return nil, false, diags
+	}
+	attribute_type, type_diags := state.Schema.TypeAtPath(ctx, state_path)
+	diags.Append(type_diags...)
+	if diags.HasError() {
+		return nil, false, diags
+	}
+	value, err := attribute_type.ValueFromTerraform(ctx, prior)
+	if err != nil {
+		diags.AddAttributeError(attribute_path, "Error reading prior value", err.Error())
+		return nil, false, diags
+	}
+	return value, false, diags
+}
+
+func (m serverDefaultModifier) PlanModifyBool(ctx context.Context, req planmodifier.BoolRequest, resp *planmodifier.BoolResponse) {
+	if !req.ConfigValue.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+	value, reset, diags := m.priorValue(ctx, req.Path, req.Plan, req.State, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if reset {
+		resp.PlanValue = types.BoolUnknown()
+	}
+	if value == nil {
+		return
+	}
+	resp.PlanValue, diags = value.(basetypes.BoolValuable).ToBoolValue(ctx)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (m serverDefaultModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+	value, reset, diags := m.priorValue(ctx, req.Path, req.Plan, req.State, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if reset {
+		resp.PlanValue = types.StringUnknown()
+	}
+	if value == nil {
+		return
+	}
+	resp.PlanValue, diags = value.(basetypes.StringValuable).ToStringValue(ctx)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (m serverDefaultModifier) PlanModifyObject(ctx context.Context, req planmodifier.ObjectRequest, resp *planmodifier.ObjectResponse) {
+	if !req.ConfigValue.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+	value, reset, diags := m.priorValue(ctx, req.Path, req.Plan, req.State, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if reset {
+		resp.PlanValue = types.ObjectUnknown(req.PlanValue.AttributeTypes(ctx))
+	}
+	if value == nil {
+		return
+	}
+	resp.PlanValue, diags = value.(basetypes.ObjectValuable).ToObjectValue(ctx)
+	resp.Diagnostics.Append(diags...)
+}
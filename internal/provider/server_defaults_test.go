@@ -0,0 +1,415 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testTerraform drives the provider over the plugin protocol the way
+// Terraform does for plan, apply and refresh of a single cdnvideo_http.
+type testTerraform struct {
+	t      *testing.T
+	server tfprotov6.ProviderServer
+	schema schema.Schema
+
+	// private is the private state of the resource, passed along with its
+	// state like Terraform does, and planned_private that of the last plan
+	private         []byte
+	planned_private []byte
+}
+
+func newTestTerraform(t *testing.T, api *fakeAPI) *testTerraform {
+	for _, env := range []string{"CDN_ACCOUNT_NAME", "CDN_USERNAME", "CDN_PASSWORD", "CDN_API_TOKEN", "CDN_API_URL", "CDN_OAUTH_URL"} {
+		t.Setenv(env, "")
+	}
+
+	server := providerserver.NewProtocol6(New("test")())()
+	provider_config := testProviderConfig(t, map[string]tftypes.Value{
+		"account_name": tftypes.NewValue(tftypes.String, "account"),
+		"api_token":    tftypes.NewValue(tftypes.String, "token"),
+		"api_url":      tftypes.NewValue(tftypes.String, api.server.URL+"/api"),
+		"oauth_url":    tftypes.NewValue(tftypes.String, api.server.URL+"/oauth/token/"),
+	}).Raw
+	config, err := tfprotov6.NewDynamicValue(provider_config.Type(), provider_config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.ConfigureProvider(context.Background(), &tfprotov6.ConfigureProviderRequest{Config: &config})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, "ConfigureProvider", resp.Diagnostics)
+
+	return &testTerraform{t: t, server: server, schema: httpResourceSchema(t).Schema}
+}
+
+func checkDiagnostics(t *testing.T, step string, diagnostics []*tfprotov6.Diagnostic) {
+	t.Helper()
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("%s: %s: %s", step, diagnostic.Summary, diagnostic.Detail)
+		}
+	}
+}
+
+func (tf *testTerraform) dynamicValue(value tftypes.Value) *tfprotov6.DynamicValue {
+	dynamic_value, err := tfprotov6.NewDynamicValue(value.Type(), value)
+	if err != nil {
+		tf.t.Fatal(err)
+	}
+	return &dynamic_value
+}
+
+func (tf *testTerraform) value(dynamic_value *tfprotov6.DynamicValue) tftypes.Value {
+	value, err := dynamic_value.Unmarshal(tf.schema.Type().TerraformType(context.Background()))
+	if err != nil {
+		tf.t.Fatal(err)
+	}
+	return value
+}
+
+// proposedNewState merges config and prior state like Terraform does before
+// planning: computed attributes missing from config keep their prior value.
+func (tf *testTerraform) proposedNewState(prior, config tftypes.Value) tftypes.Value {
+	if prior.IsNull() {
+		return config
+	}
+
+	proposed, err := tftypes.Transform(config, func(attribute_path *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+		if !value.IsNull() {
+			return value, nil
+		}
+		attribute, err := tf.schema.AttributeAtTerraformPath(context.Background(), attribute_path)
+		if err != nil || !attribute.IsComputed() {
+			return value, nil
+		}
+		prior_value, _, err := tftypes.WalkAttributePath(prior, attribute_path)
+		if err != nil {
+			return value, nil
+		}
+		return prior_value.(tftypes.Value), nil
+	})
+	if err != nil {
+		tf.t.Fatal(err)
+	}
+	return proposed
+}
+
+func (tf *testTerraform) plan(prior, config tftypes.Value) tftypes.Value {
+	resp, err := tf.server.PlanResourceChange(context.Background(), &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         "cdnvideo_http",
+		PriorState:       tf.dynamicValue(prior),
+		ProposedNewState: tf.dynamicValue(tf.proposedNewState(prior, config)),
+		Config:           tf.dynamicValue(config),
+		PriorPrivate:     tf.private,
+	})
+	if err != nil {
+		tf.t.Fatal(err)
+	}
+	checkDiagnostics(tf.t, "PlanResourceChange", resp.Diagnostics)
+	tf.planned_private = resp.PlannedPrivate
+	return tf.value(resp.PlannedState)
+}
+
+func (tf *testTerraform) apply(prior, planned, config tftypes.Value) tftypes.Value {
+	resp, err := tf.server.ApplyResourceChange(context.Background(), &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       "cdnvideo_http",
+		PriorState:     tf.dynamicValue(prior),
+		PlannedState:   tf.dynamicValue(planned),
+		Config:         tf.dynamicValue(config),
+		PlannedPrivate: tf.planned_private,
+	})
+	if err != nil {
+		tf.t.Fatal(err)
+	}
+	checkDiagnostics(tf.t, "ApplyResourceChange", resp.Diagnostics)
+	tf.private = resp.Private
+	return tf.value(resp.NewState)
+}
+
+func (tf *testTerraform) refresh(state tftypes.Value) tftypes.Value {
+	resp, err := tf.server.ReadResource(context.Background(), &tfprotov6.ReadResourceRequest{
+		TypeName:     "cdnvideo_http",
+		CurrentState: tf.dynamicValue(state),
+		Private:      tf.private,
+	})
+	if err != nil {
+		tf.t.Fatal(err)
+	}
+	checkDiagnostics(tf.t, "ReadResource", resp.Diagnostics)
+	tf.private = resp.Private
+	return tf.value(resp.NewState)
+}
+
+// importState imports the resource with id and refreshes it.
+func (tf *testTerraform) importState(id string) tftypes.Value {
+	resp, err := tf.server.ImportResourceState(context.Background(), &tfprotov6.ImportResourceStateRequest{
+		TypeName: "cdnvideo_http",
+		ID:       id,
+	})
+	if err != nil {
+		tf.t.Fatal(err)
+	}
+	checkDiagnostics(tf.t, "ImportResourceState", resp.Diagnostics)
+	if len(resp.ImportedResources) != 1 {
+		tf.t.Fatalf("expected one imported resource, got %d", len(resp.ImportedResources))
+	}
+	tf.private = resp.ImportedResources[0].Private
+	return tf.refresh(tf.value(resp.ImportedResources[0].State))
+}
+
+// expectNoChanges fails the test when planning config against state would
+// change anything.
+func (tf *testTerraform) expectNoChanges(step string, state, config tftypes.Value) {
+	tf.t.Helper()
+
+	planned := tf.plan(state, config)
+	if planned.Equal(state) {
+		return
+	}
+	diff, _ := state.Diff(planned)
+	tf.t.Errorf("%s: expected an empty plan, got changes:", step)
+	for _, change := range diff {
+		tf.t.Errorf("  %s: %s -> %s", change.Path, change.Value1, change.Value2)
+	}
+}
+
+// testResourceConfig returns the configuration of http_resource, with
+// computed-only and defaulted attributes left unset.
+func testResourceConfig(t *testing.T, http_resource configuration.CdnHttpResource) tftypes.Value {
+	model := testModel(t, http_resource)
+	model.ID = types.StringNull()
+	model.CdnDomain = types.StringNull()
+	model.CreationTs = types.Int64Null()
+	model.Active = types.BoolNull()
+	model.RemoveDeactivated = types.BoolNull()
+	model.DeletionMode = types.StringNull()
+	model.DeletionProtection = types.BoolNull()
+	model.AdoptExisting = types.BoolNull()
+	model.ForceOverwrite = types.BoolNull()
+	// Tasks are not waited for, polling would slow the tests down
+	model.WaitForDeployment = types.BoolValue(false)
+	return testState(t, model).Raw
+}
+
+// setDefault sets key in object to value unless it is set already.
+func setDefault(object map[string]any, key string, value any) map[string]any {
+	if _, ok := object[key]; !ok {
+		object[key] = value
+	}
+	nested, _ := object[key].(map[string]any)
+	return nested
+}
+
+// injectServerDefaults fills in settings the way the api does for
+// attributes missing from a request.
+func injectServerDefaults(resource map[string]any) {
+	for _, key := range []string{"modern_tls_only", "strong_ssl_ciphers", "follow_redirects", "no_http2", "http2https", "https_only", "use_http3", "ioss"} {
+		setDefault(resource, key, false)
+	}
+	setDefault(resource, "tuning", "default")
+	injectBlockDefaults(resource)
+
+	if locations, ok := resource["locations"].(*fakeLocations); ok {
+		for _, location := range locations.values {
+			if location, ok := location.(map[string]any); ok {
+				injectBlockDefaults(location)
+			}
+		}
+	}
+}
+
+// injectBlockDefaults fills in the defaults of the blocks that a resource and
+// its locations have in common.
+func injectBlockDefaults(resource map[string]any) {
+	if origin, ok := resource["origin"].(map[string]any); ok {
+		setDefault(origin, "https", false)
+		setDefault(origin, "ssl_verify", false)
+		for _, key := range []string{"read_timeout", "send_timeout", "connect_timeout"} {
+			setDefault(origin, key, "60s")
+		}
+	}
+
+	cache := setDefault(resource, "cache", map[string]any{})
+	for _, key := range []string{"disable", "consider_args", "consider_cookies", "use_stale"} {
+		setDefault(cache, key, false)
+	}
+	setDefault(setDefault(cache, "valid", map[string]any{}), "force", false)
+
+	compress := setDefault(resource, "compress", map[string]any{})
+	setDefault(compress, "gzip", true)
+	setDefault(compress, "brotli", false)
+
+	if cors, ok := resource["cors"].(map[string]any); ok {
+		setDefault(cors, "credentials", false)
+		setDefault(cors, "disable", false)
+	}
+}
+
+func TestServerDefaultsPlanNoChanges(t *testing.T) {
+	minimal := `{
+		"name": "testname",
+		"origin": {"servers": {"example.com": {"port": 443}}}
+	}`
+	cases := map[string]struct {
+		configured string
+		// updated is the configuration applied next, the resource is renamed
+		// when it is empty
+		updated string
+		check   func(t *testing.T, stored map[string]any)
+	}{
+		"minimal": {configured: minimal},
+		"partial blocks": {configured: `{
+			"name": "testname",
+			"origin": {"servers": {"example.com": {"port": 443}}, "https": true},
+			"cache": {"consider_args": true, "args_whitelist": ["page"], "valid": {"2xx": "1d"}},
+			"cors": {"domains": ["example.com"]}
+		}`},
+		"explicit values": {configured: `{
+			"name": "testname",
+			"origin": {"servers": {"example.com": {"port": 443}}, "https": true, "read_timeout": "10s"},
+			"tuning": "large",
+			"https_only": true,
+			"compress": {"brotli": true}
+		}`},
+		"locations": {configured: `{
+			"name": "testname",
+			"origin": {"servers": {"example.com": {"port": 443}}},
+			"locations": {"/video": {"cache": {"valid": {"2xx": "7d"}}, "compress": {"gzip": false}}}
+		}`},
+		"remove configured": {
+			configured: `{
+				"name": "testname",
+				"origin": {"servers": {"example.com": {"port": 443}}, "read_timeout": "10s"},
+				"cache": {"consider_args": true, "args_whitelist": ["p"], "valid": {"2xx": "7d"}},
+				"tuning": "large"
+			}`,
+			updated: minimal,
+			check: func(t *testing.T, stored map[string]any) {
+				cache := stored["cache"].(map[string]any)
+				if cache["args_whitelist"] != nil || cache["consider_args"] != false || cache["valid"].(map[string]any)["2xx"] != nil {
+					t.Errorf("expected the cache settings to be removed, got %v", cache)
+				}
+				if timeout := stored["origin"].(map[string]any)["read_timeout"]; timeout != "60s" {
+					t.Errorf("expected read_timeout to be reset, got %v", timeout)
+				}
+				if stored["tuning"] != "default" {
+					t.Errorf("expected tuning to be reset, got %v", stored["tuning"])
+				}
+			},
+		},
+		// Terraform proposes the prior value of computed attributes removed
+		// from the configuration, so the plan only changes if the provider
+		// resets them
+		"remove configured computed": {
+			configured: `{
+				"name": "testname",
+				"origin": {"servers": {"example.com": {"port": 443}}, "read_timeout": "10s"},
+				"compress": {"brotli": true},
+				"tuning": "large"
+			}`,
+			updated: minimal,
+			check: func(t *testing.T, stored map[string]any) {
+				if compress := stored["compress"].(map[string]any); compress["brotli"] != false {
+					t.Errorf("expected compress to be reset, got %v", compress)
+				}
+				if timeout := stored["origin"].(map[string]any)["read_timeout"]; timeout != "60s" {
+					t.Errorf("expected read_timeout to be reset, got %v", timeout)
+				}
+				if stored["tuning"] != "default" {
+					t.Errorf("expected tuning to be reset, got %v", stored["tuning"])
+				}
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			api.normalize = injectServerDefaults
+			tf := newTestTerraform(t, api)
+
+			var http_resource configuration.CdnHttpResource
+			if err := json.Unmarshal([]byte(test.configured), &http_resource); err != nil {
+				t.Fatal(err)
+			}
+			config := testResourceConfig(t, http_resource)
+			null_state := tftypes.NewValue(config.Type(), nil)
+
+			state := tf.apply(null_state, tf.plan(null_state, config), config)
+			state = tf.refresh(state)
+			tf.expectNoChanges("create", state, config)
+
+			if test.updated != "" {
+				http_resource = configuration.CdnHttpResource{}
+				if err := json.Unmarshal([]byte(test.updated), &http_resource); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				http_resource.Name = "renamed"
+			}
+			config = testResourceConfig(t, http_resource)
+			planned := tf.plan(state, config)
+			if planned.Equal(state) {
+				t.Fatal("expected the update to be planned, got an empty plan")
+			}
+			state = tf.refresh(tf.apply(state, planned, config))
+			tf.expectNoChanges("update", state, config)
+
+			if test.check != nil {
+				stored, _ := api.get("1")
+				test.check(t, stored)
+			}
+		})
+	}
+}
+
+func TestServerDefaultsImport(t *testing.T) {
+	api := newFakeAPI(t)
+	api.normalize = injectServerDefaults
+	tf := newTestTerraform(t, api)
+
+	var http_resource configuration.CdnHttpResource
+	if err := json.Unmarshal([]byte(`{
+		"name": "testname",
+		"origin": {"servers": {"example.com": {"port": 443}}},
+		"cache": {"valid": {"2xx": "1d"}},
+		"locations": {"/video": {"compress": {"gzip": false}}}
+	}`), &http_resource); err != nil {
+		t.Fatal(err)
+	}
+	config := testResourceConfig(t, http_resource)
+	null_state := tftypes.NewValue(config.Type(), nil)
+	tf.apply(null_state, tf.plan(null_state, config), config)
+
+	// Nothing is known about which attributes were configured after an
+	// import, the values read are kept as they are
+	tf.private = nil
+	state := tf.importState("1")
+	diff, err := state.Diff(tf.plan(state, config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wait_for_deployment := tftypes.NewAttributePath().WithAttributeName("wait_for_deployment")
+	for _, change := range diff {
+		// Tests do not wait for deployment, imports do by default
+		if !change.Path.Equal(wait_for_deployment) {
+			t.Errorf("import: unexpected change of %s: %s -> %s", change.Path, change.Value1, change.Value2)
+		}
+	}
+
+	http_resource.Name = "renamed"
+	config = testResourceConfig(t, http_resource)
+	state = tf.refresh(tf.apply(state, tf.plan(state, config), config))
+	tf.expectNoChanges("update", state, config)
+}
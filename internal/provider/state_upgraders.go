@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// httpResourceSchemaVersion is the current version of the cdnvideo_http
+// schema. Changing the shape of existing attributes requires bumping it and
+// adding an upgrade step from the previous version.
+const httpResourceSchemaVersion = 2
+
+// httpResourceStateUpgrades migrate raw json state from the schema version
+// equal to their index to the next one. Older states are upgraded by running
+// every step from their version on, so each step only knows about its own
+// change.
+var httpResourceStateUpgrades = []func(state map[string]any) error{
+	upgradeHttpResourceStateV0,
+	upgradeHttpResourceStateV1,
+}
+
+// UpgradeState registers an upgrader for every prior schema version.
+func (d *httpResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	upgraders := make(map[int64]resource.StateUpgrader, len(httpResourceStateUpgrades))
+	for version := range httpResourceStateUpgrades {
+		upgraders[int64(version)] = resource.StateUpgrader{
+			StateUpgrader: upgradeHttpResourceState(int64(version)),
+		}
+	}
+	return upgraders
+}
+
+// upgradeHttpResourceState returns an upgrader taking raw state of the given
+// schema version to the current one.
+func upgradeHttpResourceState(from int64) func(context.Context, resource.UpgradeStateRequest, *resource.UpgradeStateResponse) {
+	return func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+		if req.RawState == nil || req.RawState.JSON == nil {
+			resp.Diagnostics.AddError(
+				"Unable to upgrade cdn http resource state",
+				fmt.Sprintf("State of schema version %d has no json representation. Please report this to the provider developers.", from),
+			)
+			return
+		}
+
+		// Keep numbers as written, creation_ts and certificate ids must not
+		// lose precision on the way through float64
+		decoder := json.NewDecoder(bytes.NewReader(req.RawState.JSON))
+		decoder.UseNumber()
+		state := map[string]any{}
+		if err := decoder.Decode(&state); err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to upgrade cdn http resource state",
+				fmt.Sprintf("Could not decode state of schema version %d, unexpected error: %s", from, err),
+			)
+			return
+		}
+
+		for version := from; version < httpResourceSchemaVersion; version++ {
+			if err := httpResourceStateUpgrades[version](state); err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to upgrade cdn http resource state",
+					fmt.Sprintf("Could not upgrade state from schema version %d to %d, unexpected error: %s", version, version+1, err),
+				)
+				return
+			}
+		}
+
+		upgraded, err := json.Marshal(state)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to upgrade cdn http resource state",
+				"Could not encode upgraded state, unexpected error: "+err.Error(),
+			)
+			return
+		}
+		resp.DynamicValue = &tfprotov6.DynamicValue{JSON: upgraded}
+
+		tflog.Debug(ctx, "Upgraded cdn http resource state", map[string]any{
+			"from_version": from,
+			"to_version":   httpResourceSchemaVersion,
+		})
+	}
+}
+
+// upgradeHttpResourceStateV0 adds the provider settings that version 0 did
+// not have, with their defaults, so that upgraded resources plan no changes.
+func upgradeHttpResourceStateV0(state map[string]any) error {
+	if _, ok := state["id"]; !ok {
+		return fmt.Errorf("state has no id attribute")
+	}
+
+	defaults := map[string]any{
+		"wait_for_deployment": true,
+		"remove_deactivated":  false,
+		"deletion_mode":       deletionModeDeactivate,
+		"deletion_protection": false,
+		"adopt_existing":      false,
+		"force_overwrite":     false,
+		"timeouts":            nil,
+	}
+	for key, value := range defaults {
+		if _, ok := state[key]; !ok {
+			state[key] = value
+		}
+	}
+
+	return nil
+}
+
+// upgradeHttpResourceStateV1 turns the locations map, keyed by api location
+// key, into the location list. Maps have no order, so the locations are
+// listed by key, which is also the order the map was planned in.
+func upgradeHttpResourceStateV1(state map[string]any) error {
+	locations, ok := state["locations"].(map[string]any)
+	delete(state, "locations")
+	if !ok {
+		state["location"] = nil
+		return nil
+	}
+
+	keys := make([]string, 0, len(locations))
+	for key := range locations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	location_list := make([]any, 0, len(keys))
+	for _, key := range keys {
+		location, ok := locations[key].(map[string]any)
+		if !ok {
+			return fmt.Errorf("location %q is not an object", key)
+		}
+		location["match"], location["path"] = configuration.ParseLocationKey(key)
+		location_list = append(location_list, location)
+	}
+	state["location"] = location_list
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// upgradeState runs raw json state of the given schema version through the
+// provider server as Terraform does when loading an older state file.
+func upgradeState(t *testing.T, version int64, raw_state []byte) (tfsdk.State, []*tfprotov6.Diagnostic) {
+	server := providerserver.NewProtocol6(New("test")())()
+	resp, err := server.UpgradeResourceState(context.Background(), &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "cdnvideo_http",
+		Version:  version,
+		RawState: &tfprotov6.RawState{JSON: raw_state},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := httpResourceSchema(t).Schema
+	state := tfsdk.State{Schema: schema}
+	if resp.UpgradedState != nil {
+		state.Raw, err = resp.UpgradedState.Unmarshal(schema.Type().TerraformType(context.Background()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return state, resp.Diagnostics
+}
+
+func TestUpgradeStateFromV0(t *testing.T) {
+	raw_state, err := os.ReadFile("testdata/http_state_v0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, diagnostics := upgradeState(t, 0, raw_state)
+	if len(diagnostics) > 0 {
+		t.Fatalf("UpgradeResourceState: %v", diagnostics[0])
+	}
+
+	model := stateModel(t, state)
+	if model.ID.ValueString() != "42" || model.Name.ValueString() != "example" || model.CreationTs.ValueInt64() != 1700000042 {
+		t.Errorf("expected api attributes to be kept, got id %s, name %s, creation_ts %s", model.ID, model.Name, model.CreationTs)
+	}
+	if model.Origin.ReadTimeout.ValueString() != "10s" || len(model.Location.Elements()) != 1 || len(model.Names.Elements()) != 2 {
+		t.Errorf("expected nested attributes to be kept, got origin %v, location %s, names %s", model.Origin, model.Location, model.Names)
+	}
+
+	if !model.WaitForDeployment.ValueBool() ||
+		model.RemoveDeactivated.ValueBool() ||
+		model.DeletionMode.ValueString() != deletionModeDeactivate ||
+		model.DeletionProtection.ValueBool() ||
+		model.AdoptExisting.ValueBool() ||
+		model.ForceOverwrite.ValueBool() {
+		t.Errorf("expected provider settings to be set to their defaults, got %+v", model)
+	}
+	if !model.Timeouts.IsNull() {
+		t.Errorf("expected timeouts to be null, got %s", model.Timeouts)
+	}
+}
+
+func TestUpgradeStateFromV1(t *testing.T) {
+	raw_state := `{
+		"id": "42",
+		"name": "example",
+		"origin": {"servers": {"origin.example.com": {"port": 443}}},
+		"locations": {
+			"/static": {"ioss": true},
+			"~* \\.mp4$": {"return_http_status_code": 403},
+			"= /health": {"return_http_status_code": 204},
+			"/images": {"ioss": false}
+		},
+		"wait_for_deployment": true,
+		"remove_deactivated": false,
+		"deletion_mode": "deactivate",
+		"deletion_protection": false,
+		"adopt_existing": false,
+		"force_overwrite": false
+	}`
+
+	state, diagnostics := upgradeState(t, 1, []byte(raw_state))
+	if len(diagnostics) > 0 {
+		t.Fatalf("UpgradeResourceState: %v", diagnostics[0])
+	}
+
+	http_resource, diags := GenerateApiRequest(stateModel(t, state), context.Background())
+	if diags.HasError() {
+		t.Fatalf("GenerateApiRequest: %v", diags)
+	}
+	locations := []string{}
+	for _, location := range http_resource.Locations {
+		locations = append(locations, *location.Match+" "+location.Path)
+	}
+
+	expected := `[prefix /images prefix /static exact /health regex_case_insensitive \.mp4$]`
+	if actual := fmt.Sprint(locations); actual != expected {
+		t.Errorf("expected locations %s, got %s", expected, actual)
+	}
+	expected_keys := `["/images" "/static" "= /health" "~* \\.mp4$"]`
+	if keys := fmt.Sprintf("%q", http_resource.Locations.Keys()); keys != expected_keys {
+		t.Errorf("expected the api keys to be kept, got %s", keys)
+	}
+}
+
+func TestUpgradeStateInvalid(t *testing.T) {
+	for name, raw_state := range map[string]string{
+		"not json":   `{"id": `,
+		"without id": `{"name": "example"}`,
+	} {
+		if _, diagnostics := upgradeState(t, 0, []byte(raw_state)); len(diagnostics) == 0 {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
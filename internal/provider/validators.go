@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ConfigValidator = requiresSiblingValueValidator{}
+	_ resource.ConfigValidator = locationRulesValidator{}
+)
+
+// requiresSiblingValueValidator checks that whenever one of the attributes is
+// set, the attribute named sibling next to it has the given value. When is
+// optional and restricts the check to a specific value of the attribute,
+// e.g. only true for a bool flag.
+type requiresSiblingValueValidator struct {
+	attributes []path.Expression
+	when       attr.Value
+	sibling    string
+	value      attr.Value
+}
+
+// requiresSiblingValue returns a validator checking that attribute can only be
+// set when its sibling attribute equals value.
+func requiresSiblingValue(attribute path.Expression, sibling string, value attr.Value) requiresSiblingValueValidator {
+	return requiresSiblingValueValidator{attributes: []path.Expression{attribute}, sibling: sibling, value: value}
+}
+
+// In additionally checks the attribute at the given paths.
+func (v requiresSiblingValueValidator) In(attributes ...path.Expression) requiresSiblingValueValidator {
+	v.attributes = append(append([]path.Expression{}, v.attributes...), attributes...)
+	return v
+}
+
+// When restricts the check to the attribute having the given value.
+func (v requiresSiblingValueValidator) When(value attr.Value) requiresSiblingValueValidator {
+	v.when = value
+	return v
+}
+
+func (v requiresSiblingValueValidator) Description(_ context.Context) string {
+	condition := "is set"
+	if v.when != nil {
+		condition = "is " + v.when.String()
+	}
+	return fmt.Sprintf("When %s %s, %s must be %s", v.attributes[0], condition, v.sibling, v.value)
+}
+
+func (v requiresSiblingValueValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v requiresSiblingValueValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	for _, expression := range v.attributes {
+		matches, diags := req.Config.PathMatches(ctx, expression)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			return
+		}
+
+		for _, match := range matches {
+			var value attr.Value
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, match, &value)...)
+			// Null parents are matched as well, they have nothing to check
+			if value == nil || value.IsNull() || value.IsUnknown() || !expression.Matches(match) {
+				continue
+			}
+			if v.when != nil && !value.Equal(v.when) {
+				continue
+			}
+
+			sibling_path := match.ParentPath().AtName(v.sibling)
+			var sibling attr.Value
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, sibling_path, &sibling)...)
+			if sibling == nil || sibling.IsUnknown() || sibling.Equal(v.value) {
+				continue
+			}
+
+			condition := "set"
+			if v.when != nil {
+				condition = v.when.String()
+			}
+			resp.Diagnostics.AddAttributeError(
+				match,
+				"Invalid Attribute Combination",
+				fmt.Sprintf("%s can only be %s when %s is %s", match, condition, sibling_path, v.value),
+			)
+		}
+	}
+}
+
+// locationRulesValidator checks that the paths of regex locations compile
+// and that no two locations have the same path and match type, which the api
+// could not tell apart.
+type locationRulesValidator struct{}
+
+func (v locationRulesValidator) Description(_ context.Context) string {
+	return "Location paths must be valid regular expressions for the regex match types and unique per match type"
+}
+
+func (v locationRulesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v locationRulesValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var locations types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("location"), &locations)...)
+	if resp.Diagnostics.HasError() || locations.IsNull() || locations.IsUnknown() {
+		return
+	}
+
+	seen := map[string]int{}
+	for i, element := range locations.Elements() {
+		location, ok := element.(types.Object)
+		if !ok || location.IsNull() || location.IsUnknown() {
+			continue
+		}
+		location_path, _ := location.Attributes()["path"].(types.String)
+		match, _ := location.Attributes()["match"].(types.String)
+		if location_path.IsNull() || location_path.IsUnknown() || match.IsUnknown() {
+			continue
+		}
+
+		match_type := configuration.LocationMatchPrefix
+		if !match.IsNull() {
+			match_type = match.ValueString()
+		}
+		attribute_path := path.Root("location").AtListIndex(i).AtName("path")
+
+		if match_type == configuration.LocationMatchRegex || match_type == configuration.LocationMatchRegexCaseInsensitive {
+			if _, err := regexp.Compile(location_path.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					attribute_path,
+					"Invalid Location Regular Expression",
+					fmt.Sprintf("%q is not a valid regular expression: %s. Regular expressions use RE2 syntax, which does not support lookaround or backreferences.", location_path.ValueString(), err),
+				)
+			}
+		}
+
+		// nginx refuses a prefix and a priority_prefix location on the same path
+		key_match := match_type
+		if key_match == configuration.LocationMatchPriorityPrefix {
+			key_match = configuration.LocationMatchPrefix
+		}
+		key := configuration.Location{Path: location_path.ValueString(), Match: &key_match}.Key()
+		if first, ok := seen[key]; ok {
+			resp.Diagnostics.AddAttributeError(
+				attribute_path,
+				"Duplicate Location",
+				fmt.Sprintf("location[%d] has the same path and match as location[%d]", i, first),
+			)
+			continue
+		}
+		seen[key] = i
+	}
+}
@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// validateConfig runs the full resource config validation for a model as
+// Terraform does on validate and plan, returning the error diagnostics.
+func validateConfig(t *testing.T, model CdnHttpResourceModel) []*tfprotov6.Diagnostic {
+	model.ID = types.StringNull()
+	model.CdnDomain = types.StringNull()
+	model.CreationTs = types.Int64Null()
+	config := testState(t, model).Raw
+
+	value, err := tfprotov6.NewDynamicValue(config.Type(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := providerserver.NewProtocol6(New("test")())()
+	resp, err := server.ValidateResourceConfig(context.Background(), &tfprotov6.ValidateResourceConfigRequest{
+		TypeName: "cdnvideo_http",
+		Config:   &value,
+		ClientCapabilities: &tfprotov6.ValidateResourceConfigClientCapabilities{
+			WriteOnlyAttributesAllowed: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errors []*tfprotov6.Diagnostic
+	for _, diagnostic := range resp.Diagnostics {
+		if diagnostic.Severity == tfprotov6.DiagnosticSeverityError {
+			errors = append(errors, diagnostic)
+		}
+	}
+	return errors
+}
+
+func TestValidateConfig(t *testing.T) {
+	str := func(value string) *string { return &value }
+	boolean := func(value bool) *bool { return &value }
+	port := func(value int) *int { return &value }
+	size := int64(8)
+	version := int64(1)
+
+	cases := map[string]struct {
+		update   func(http_resource *configuration.CdnHttpResource)
+		path     string
+		contains string
+	}{
+		"valid": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Tuning = str("large")
+				http_resource.SliceSizeMegabytes = &size
+				http_resource.ModernTlsOnly = boolean(true)
+				http_resource.StrongSslCiphers = boolean(true)
+				http_resource.Robots = &configuration.Robots{Type: str("custom"), RobotsContent: str("User-agent: *")}
+			},
+		},
+		"tuning": {
+			update:   func(http_resource *configuration.CdnHttpResource) { http_resource.Tuning = str("fast") },
+			path:     `AttributeName("tuning")`,
+			contains: "default",
+		},
+		"slice size without large tuning": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Tuning = str("live")
+				http_resource.SliceSizeMegabytes = &size
+			},
+			path:     `AttributeName("slice_size_megabytes")`,
+			contains: `tuning is "large"`,
+		},
+		"strong ciphers without modern tls": {
+			update:   func(http_resource *configuration.CdnHttpResource) { http_resource.StrongSslCiphers = boolean(true) },
+			path:     `AttributeName("strong_ssl_ciphers")`,
+			contains: "modern_tls_only is true",
+		},
+		"robots type": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Robots = &configuration.Robots{Type: str("allow")}
+			},
+			path:     `AttributeName("robots").AttributeName("type")`,
+			contains: "cached",
+		},
+		"port": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Origin.Servers["example.com"] = configuration.Servers{Port: port(70000)}
+			},
+			path:     `AttributeName("origin").AttributeName("servers").ElementKeyString("example.com").AttributeName("port")`,
+			contains: "65535",
+		},
+		"sni hostname in location without https": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Locations = configuration.LocationList{{Path: "/video", Locations: configuration.Locations{
+					Origin: &configuration.Origin{Servers: http_resource.Origin.Servers, SNIHostname: str("example.com")},
+				}}}
+			},
+			path:     `AttributeName("location").ElementKeyInt(0).AttributeName("origin").AttributeName("sni_hostname")`,
+			contains: "https is true",
+		},
+		"locations": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Locations = configuration.LocationList{
+					{Path: "/video"},
+					{Path: "/video", Match: str(configuration.LocationMatchExact)},
+					{Path: `\.(mp4|webm)$`, Match: str(configuration.LocationMatchRegexCaseInsensitive)},
+					{Path: "/images", Match: str(configuration.LocationMatchPriorityPrefix)},
+				}
+			},
+		},
+		"invalid location regex": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Locations = configuration.LocationList{{Path: `/(?!private)`, Match: str(configuration.LocationMatchRegex)}}
+			},
+			path:     `AttributeName("location").ElementKeyInt(0).AttributeName("path")`,
+			contains: "RE2",
+		},
+		"prefix location path is not a regex": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Locations = configuration.LocationList{{Path: "/(?!private)"}}
+			},
+		},
+		"location match": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Locations = configuration.LocationList{{Path: "/video", Match: str("suffix")}}
+			},
+			path:     `AttributeName("location").ElementKeyInt(0).AttributeName("match")`,
+			contains: "regex_case_insensitive",
+		},
+		"duplicate location": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Locations = configuration.LocationList{
+					{Path: "/video", Match: str(configuration.LocationMatchPrefix)},
+					{Path: "/images"},
+					{Path: "/video"},
+				}
+			},
+			path:     `AttributeName("location").ElementKeyInt(2).AttributeName("path")`,
+			contains: "location[0]",
+		},
+		"duplicate priority prefix location": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Locations = configuration.LocationList{
+					{Path: "/video", Match: str(configuration.LocationMatchPriorityPrefix)},
+					{Path: "/video"},
+				}
+			},
+			path:     `AttributeName("location").ElementKeyInt(1).AttributeName("path")`,
+			contains: "location[0]",
+		},
+		"aws auth without access key": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Origin.AWS = &configuration.AWS{Auth: &configuration.AWSAuth{SecretKey: str("secret")}}
+			},
+			path:     `AttributeName("origin").AttributeName("aws").AttributeName("auth").AttributeName("access_key")`,
+			contains: "access_key_wo",
+		},
+		"secret and write-only secret": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Auth = &configuration.Auth{Md5: &configuration.Md5Auth{Secret: str("secret"), SecretWO: str("secret"), SecretWOVersion: &version}}
+			},
+			path:     `AttributeName("auth").AttributeName("md5").AttributeName("secret")`,
+			contains: "secret_wo",
+		},
+		"write-only secret without version": {
+			update: func(http_resource *configuration.CdnHttpResource) {
+				http_resource.Auth = &configuration.Auth{Md5: &configuration.Md5Auth{SecretWO: str("secret")}}
+			},
+			path:     `AttributeName("auth").AttributeName("md5").AttributeName("secret_wo")`,
+			contains: "secret_wo_version",
+		},
+	}
+
+	for name, c := range cases {
+		http_resource := testApiResource()
+		c.update(&http_resource)
+
+		errors := validateConfig(t, testModel(t, http_resource))
+		if c.path == "" {
+			if len(errors) != 0 {
+				t.Errorf("%s: unexpected errors: %v", name, errors)
+			}
+			continue
+		}
+
+		if len(errors) != 1 {
+			t.Errorf("%s: expected one error, got %v", name, errors)
+			continue
+		}
+		if path := errors[0].Attribute.String(); path != c.path {
+			t.Errorf("%s: expected error at %s, got %s", name, c.path, path)
+		}
+		if !strings.Contains(errors[0].Detail, c.contains) {
+			t.Errorf("%s: expected %q in %q", name, c.contains, errors[0].Detail)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"terraform-provider-cdnvideo/internal/configuration"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const writeOnlyVersionSuffix = "_wo_version"
+
+// applyWriteOnlySecrets puts the values of write-only secrets, which are
+// null in the plan and only available in the configuration, into request in
+// place of the plain secrets they replace.
+func applyWriteOnlySecrets(ctx context.Context, config tfsdk.Config, request *configuration.CdnHttpResource) diag.Diagnostics {
+	var config_model CdnHttpResourceModel
+	all_diags := config.Get(ctx, &config_model)
+	if all_diags.HasError() {
+		return all_diags
+	}
+
+	config_request, diags := GenerateApiRequest(config_model, ctx)
+	all_diags.Append(diags...)
+	if all_diags.HasError() {
+		return all_diags
+	}
+
+	applyOriginSecrets(request.Origin, config_request.Origin)
+	applyAuthSecrets(request.Auth, config_request.Auth)
+	for i, location := range request.Locations {
+		// The plan lists locations in the order of the configuration
+		if i >= len(config_request.Locations) || config_request.Locations[i].Key() != location.Key() {
+			continue
+		}
+		config_location := config_request.Locations[i]
+		applyOriginSecrets(location.Origin, config_location.Origin)
+		applyAuthSecrets(location.Auth, config_location.Auth)
+	}
+
+	return all_diags
+}
+
+func applyOriginSecrets(origin, config *configuration.Origin) {
+	if origin == nil || config == nil || origin.AWS == nil || config.AWS == nil || origin.AWS.Auth == nil || config.AWS.Auth == nil {
+		return
+	}
+	if config.AWS.Auth.AccessKeyWO != nil {
+		origin.AWS.Auth.AccessKey = config.AWS.Auth.AccessKeyWO
+	}
+	if config.AWS.Auth.SecretKeyWO != nil {
+		origin.AWS.Auth.SecretKey = config.AWS.Auth.SecretKeyWO
+	}
+}
+
+func applyAuthSecrets(auth, config *configuration.Auth) {
+	if auth == nil || config == nil || auth.Md5 == nil || config.Md5 == nil {
+		return
+	}
+	if config.Md5.SecretWO != nil {
+		auth.Md5.Secret = config.Md5.SecretWO
+	}
+}
+
+// keepWriteOnlyVersions returns the actual value read from the api with the
+// versions of write-only secrets taken from the planned or prior value, as
+// the api does not know them. Plain secrets whose write-only variant is in use
+// are set to null, so that the secret read back from the api does not end
+// up in state.
+func keepWriteOnlyVersions(from, actual tftypes.Value) tftypes.Value {
+	if from.Type() == nil || !from.IsKnown() || from.IsNull() || !actual.IsKnown() || actual.IsNull() || !from.Type().Equal(actual.Type()) {
+		return actual
+	}
+
+	switch {
+	case actual.Type().Is(tftypes.Object{}):
+		var from_attributes, actual_attributes map[string]tftypes.Value
+		if from.As(&from_attributes) != nil || actual.As(&actual_attributes) != nil {
+			return actual
+		}
+
+		attributes := make(map[string]tftypes.Value, len(actual_attributes))
+		for name, value := range actual_attributes {
+			switch {
+			case strings.HasSuffix(name, writeOnlyVersionSuffix):
+				attributes[name] = from_attributes[name]
+			case isWriteOnlyInUse(from_attributes, name):
+				attributes[name] = tftypes.NewValue(value.Type(), nil)
+			default:
+				attributes[name] = keepWriteOnlyVersions(from_attributes[name], value)
+			}
+		}
+		return tftypes.NewValue(actual.Type(), attributes)
+
+	case actual.Type().Is(tftypes.Map{}):
+		var from_elements, actual_elements map[string]tftypes.Value
+		if from.As(&from_elements) != nil || actual.As(&actual_elements) != nil {
+			return actual
+		}
+
+		elements := make(map[string]tftypes.Value, len(actual_elements))
+		for key, value := range actual_elements {
+			if from_value, ok := from_elements[key]; ok {
+				value = keepWriteOnlyVersions(from_value, value)
+			}
+			elements[key] = value
+		}
+		return tftypes.NewValue(actual.Type(), elements)
+
+	case actual.Type().Is(tftypes.List{}):
+		var from_elements, actual_elements []tftypes.Value
+		if from.As(&from_elements) != nil || actual.As(&actual_elements) != nil {
+			return actual
+		}
+
+		// Locations are matched by key, their position may have changed
+		from_locations := map[string]tftypes.Value{}
+		for _, from_value := range from_elements {
+			if key, ok := locationKey(from_value); ok {
+				from_locations[key] = from_value
+			}
+		}
+
+		elements := make([]tftypes.Value, len(actual_elements))
+		for i, value := range actual_elements {
+			if key, ok := locationKey(value); ok {
+				if from_value, ok := from_locations[key]; ok {
+					value = keepWriteOnlyVersions(from_value, value)
+				}
+			} else if i < len(from_elements) {
+				value = keepWriteOnlyVersions(from_elements[i], value)
+			}
+			elements[i] = value
+		}
+		return tftypes.NewValue(actual.Type(), elements)
+	}
+
+	return actual
+}
+
+// isWriteOnlyInUse reports whether the write-only variant of the attribute
+// name has a version, which it requires whenever it is set.
+func isWriteOnlyInUse(attributes map[string]tftypes.Value, name string) bool {
+	version, ok := attributes[name+writeOnlyVersionSuffix]
+	return ok && !version.IsNull()
+}